@@ -0,0 +1,139 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"fmt"
+	"math"
+)
+
+// Translate returns a copy of the GeoJSON object moved by the offsets provided
+func Translate(gjObject interface{}, dx, dy float64) interface{} {
+	return affine(gjObject, []float64{1, 0, 0, 1, dx, dy})
+}
+
+// Scale returns a copy of the GeoJSON object scaled by the factors provided
+// about the origin, or about its centroid if the origin is nil
+func Scale(gjObject interface{}, sx, sy float64, origin *Point) interface{} {
+	x, y := affineOrigin(gjObject, origin)
+	return affine(gjObject, []float64{sx, 0, 0, sy, x - sx*x, y - sy*y})
+}
+
+// Rotate returns a copy of the GeoJSON object rotated counterclockwise
+// by the angle in degrees about the origin, or about its centroid if the origin is nil
+func Rotate(gjObject interface{}, degrees float64, origin *Point) interface{} {
+	x, y := affineOrigin(gjObject, origin)
+	sin, cos := math.Sincos(toRadians(degrees))
+	return affine(gjObject, []float64{cos, -sin, sin, cos, x - cos*x + sin*y, y - sin*x - cos*y})
+}
+
+// Affine returns a copy of the GeoJSON object with the affine transformation applied
+// to every position. A 2D matrix is given as [a, b, d, e, xoff, yoff]:
+//
+//	x' = a*x + b*y + xoff
+//	y' = d*x + e*y + yoff
+//
+// A 3D matrix is given as [a, b, c, d, e, f, g, h, i, xoff, yoff, zoff]:
+//
+//	x' = a*x + b*y + c*z + xoff
+//	y' = d*x + e*y + f*z + yoff
+//	z' = g*x + h*y + i*z + zoff
+//
+// Positions without a z value are treated as having z = 0 and remain 2D.
+// Any bbox members present on the input are recomputed.
+func Affine(gjObject interface{}, matrix []float64) (interface{}, error) {
+	if len(matrix) != 6 && len(matrix) != 12 {
+		return nil, fmt.Errorf("Affine matrix must have 6 or 12 values, not %v", len(matrix))
+	}
+	return affine(gjObject, matrix), nil
+}
+
+func affine(gjObject interface{}, matrix []float64) interface{} {
+	result, _ := mapPositions(gjObject, func(position []float64) ([]float64, error) {
+		if len(position) < 2 {
+			return position, nil
+		}
+		x, y := position[0], position[1]
+		if len(matrix) == 6 {
+			position[0] = matrix[0]*x + matrix[1]*y + matrix[4]
+			position[1] = matrix[2]*x + matrix[3]*y + matrix[5]
+			return position, nil
+		}
+		var z float64
+		if len(position) > 2 {
+			z = position[2]
+		}
+		position[0] = matrix[0]*x + matrix[1]*y + matrix[2]*z + matrix[9]
+		position[1] = matrix[3]*x + matrix[4]*y + matrix[5]*z + matrix[10]
+		if len(position) > 2 {
+			position[2] = matrix[6]*x + matrix[7]*y + matrix[8]*z + matrix[11]
+		}
+		return position, nil
+	}, planarBbox)
+	return result
+}
+
+// affineOrigin returns the position of the origin, or the centroid of the object
+func affineOrigin(gjObject interface{}, origin *Point) (float64, float64) {
+	if origin != nil && len(origin.Coordinates) >= 2 {
+		return origin.Coordinates[0], origin.Coordinates[1]
+	}
+	if center := centroid(gjObject); center != nil {
+		return center[0], center[1]
+	}
+	return 0, 0
+}
+
+// centroid returns the planar centroid of the highest dimension parts of a
+// GeoJSON object: the area-weighted centroid of its polygons, the
+// length-weighted centroid of its lines, or the mean of its points.
+// It returns nil if the object has no positions.
+func centroid(gjObject interface{}) []float64 {
+	parts := decompose(gjObject)
+	var x, y, weight float64
+	for _, polygon := range parts.polygons {
+		for inx, ring := range polygon {
+			area := math.Abs(ringArea(ring))
+			if inx > 0 {
+				area = -area
+			}
+			center := ringCentroid(ring)
+			x, y, weight = x+center[0]*area, y+center[1]*area, weight+area
+		}
+	}
+	if weight == 0 {
+		x, y = 0, 0
+		for _, segment := range parts.segments {
+			length := positionDistance(segment[0], segment[1], Planar)
+			x += (segment[0][0] + segment[1][0]) / 2 * length
+			y += (segment[0][1] + segment[1][1]) / 2 * length
+			weight += length
+		}
+	}
+	if weight == 0 {
+		x, y = 0, 0
+		positions := parts.positions()
+		for _, position := range positions {
+			x, y = x+position[0], y+position[1]
+		}
+		weight = float64(len(positions))
+	}
+	if weight == 0 {
+		return nil
+	}
+	return []float64{x / weight, y / weight}
+}
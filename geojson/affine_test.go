@@ -0,0 +1,101 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+func TestTranslateScaleRotate(t *testing.T) {
+	square := NewPolygon([][][]float64{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}})
+	square.Bbox = square.ForceBbox()
+
+	translated := Translate(square, 10, -5).(*Polygon)
+	expected := `{"type":"Polygon","coordinates":[[[10,-5],[12,-5],[12,-3],[10,-3],[10,-5]]],"bbox":[10,-5,12,-3]}`
+	if translated.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, translated.String())
+	}
+	if square.Coordinates[0][1][0] != 2 {
+		t.Error("Translate modified its input")
+	}
+
+	// About the centroid (1, 1)
+	scaled := Scale(square, 2, 3, nil).(*Polygon)
+	expected = `{"type":"Polygon","coordinates":[[[-1,-2],[3,-2],[3,4],[-1,4],[-1,-2]]],"bbox":[-1,-2,3,4]}`
+	if scaled.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, scaled.String())
+	}
+	scaled = Scale(square, 2, 2, NewPoint([]float64{0, 0})).(*Polygon)
+	if !EqualsWithin(scaled, NewPolygon([][][]float64{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}), 0) {
+		t.Errorf("Unexpected scaled polygon %v", scaled.String())
+	}
+
+	rotated := Rotate(square, 90, nil).(*Polygon)
+	if !EqualsWithin(rotated, NewPolygon([][][]float64{{{2, 0}, {2, 2}, {0, 2}, {0, 0}, {2, 0}}}), 1e-12) {
+		t.Errorf("Unexpected rotated polygon %v", rotated.String())
+	}
+	rotatedPoint := Rotate(NewPoint([]float64{1, 0, 7}), 90, NewPoint([]float64{0, 0})).(*Point)
+	if !EqualsWithin(rotatedPoint, NewPoint([]float64{0, 1, 7}), 1e-12) {
+		t.Errorf("Unexpected rotated point %v", rotatedPoint.String())
+	}
+
+	// The centroid of a line is weighted by length
+	ls := NewLineString([][]float64{{0, 0}, {4, 0}, {4, 0.5}})
+	center := centroid(ls)
+	testClose(t, "line centroid x", (2*4+4*0.5)/4.5, center[0], 1e-12)
+	holed := NewPolygon([][][]float64{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, {{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}})
+	center = centroid(holed)
+	testClose(t, "holed centroid x", (16*2-4*1)/12.0, center[0], 1e-12)
+	points := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{0, 0}), nil, nil),
+		NewFeature(NewPoint([]float64{4, 2}), nil, nil)})
+	if center = centroid(points); center == nil || center[0] != 2 || center[1] != 1 {
+		t.Errorf("Expected the collection centroid [2 1], got %v", center)
+	}
+}
+
+func TestAffine(t *testing.T) {
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewLineString([][]float64{{1, 2, 3}, {4, 5, 6}}), "a", nil),
+		NewFeature(NewPoint([]float64{1, 1}), "b", nil)})
+
+	result, err := Affine(fc, []float64{1, 0, 0, 0, 1, 0, 0, 0, 2, 10, 20, 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transformed := result.(*FeatureCollection)
+	expected := `{"type":"LineString","coordinates":[[11,22,36],[14,25,42]]}`
+	if line := transformed.Features[0].Geometry.(*LineString); line.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, line.String())
+	}
+	expected = `{"type":"Point","coordinates":[11,21]}`
+	if point := transformed.Features[1].Geometry.(*Point); point.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, point.String())
+	}
+	if transformed.Features[0].ID != "a" {
+		t.Errorf("Expected the feature ID to be kept, got %v", transformed.Features[0].ID)
+	}
+
+	// A shear
+	result, _ = Affine(NewMultiPoint([][]float64{{1, 1}, {2, 3}}), []float64{1, 1, 0, 1, 0, 0})
+	expected = `{"type":"MultiPoint","coordinates":[[2,1],[5,3]]}`
+	if result.(*MultiPoint).String() != expected {
+		t.Errorf("Expected %v, got %v", expected, result.(*MultiPoint).String())
+	}
+
+	if _, err = Affine(fc, []float64{1, 2, 3}); err == nil {
+		t.Error("Expected an error for a matrix of the wrong size")
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// SplitAntimeridian returns a GeoJSON object whose LineStrings and Polygons
+// have been cut where they cross the antimeridian, as described in
+// RFC 7946 section 3.1.9. A segment is considered to cross the antimeridian
+// when its endpoints are more than 180 degrees of longitude apart.
+// A LineString or Polygon that must be cut becomes a MultiLineString or
+// MultiPolygon; anything that does not cross is returned unchanged.
+// Polygons that encircle a pole cannot be cut this way and are also
+// returned unchanged.
+func SplitAntimeridian(gjObject interface{}) interface{} {
+	switch typedGJ := gjObject.(type) {
+	case *LineString:
+		parts := splitLineAntimeridian(typedGJ.Coordinates)
+		if len(parts) > 1 {
+			return NewMultiLineString(parts)
+		}
+	case *MultiLineString:
+		var (
+			parts [][][]float64
+			split bool
+		)
+		for _, line := range typedGJ.Coordinates {
+			lineParts := splitLineAntimeridian(line)
+			split = split || len(lineParts) > 1
+			parts = append(parts, lineParts...)
+		}
+		if split {
+			return NewMultiLineString(parts)
+		}
+	case *Polygon:
+		parts := splitPolygonAntimeridian(typedGJ.Coordinates)
+		if len(parts) > 1 {
+			return NewMultiPolygon(parts)
+		}
+	case *MultiPolygon:
+		var (
+			parts [][][][]float64
+			split bool
+		)
+		for _, polygon := range typedGJ.Coordinates {
+			polygonParts := splitPolygonAntimeridian(polygon)
+			split = split || len(polygonParts) > 1
+			parts = append(parts, polygonParts...)
+		}
+		if split {
+			return NewMultiPolygon(parts)
+		}
+	case *GeometryCollection:
+		geometries := make([]interface{}, len(typedGJ.Geometries))
+		for inx, geometry := range typedGJ.Geometries {
+			geometries[inx] = SplitAntimeridian(geometry)
+		}
+		return NewGeometryCollection(geometries)
+	case *Feature:
+		if typedGJ == nil {
+			return typedGJ
+		}
+		result := NewFeature(SplitAntimeridian(typedGJ.Geometry), typedGJ.ID, cloneProperties(typedGJ.Properties))
+		if len(typedGJ.Bbox) > 0 {
+			result.Bbox = ForceWrappedBbox(result.Geometry)
+		}
+		return result
+	case *FeatureCollection:
+		if typedGJ == nil {
+			return typedGJ
+		}
+		features := make([]*Feature, 0, len(typedGJ.Features))
+		for _, feature := range typedGJ.Features {
+			if feature != nil {
+				features = append(features, SplitAntimeridian(feature).(*Feature))
+			}
+		}
+		result := NewFeatureCollection(features)
+		if len(typedGJ.Bbox) > 0 {
+			result.Bbox = ForceWrappedBbox(result)
+		}
+		return result
+	}
+	return gjObject
+}
+
+// ForceWrappedBbox returns the smallest bounding box containing every position
+// of the GeoJSON object, allowing the box to cross the antimeridian.
+// Unlike ForceBbox, it ignores any bbox members and considers all positions,
+// so features on either side of 180° produce a narrow box rather than
+// one spanning the whole globe. The box also covers the longitudes that
+// segments between positions pass through.
+func ForceWrappedBbox(gjObject interface{}) BoundingBox {
+	positions := interfaceTo2DArray(gjObject)
+	if len(positions) == 0 {
+		return BoundingBox{}
+	}
+	result, err := NewBoundingBox(positions)
+	if err != nil || len(result) == 0 {
+		return BoundingBox{}
+	}
+	var intervals [][2]float64
+	for _, position := range positions {
+		intervals = append(intervals, [2]float64{position[0], position[0]})
+	}
+	for _, segment := range decompose(gjObject).segments {
+		west := math.Min(segment[0][0], segment[1][0])
+		east := math.Max(segment[0][0], segment[1][0])
+		if crossesAntimeridian(segment[0], segment[1]) != 0 {
+			intervals = append(intervals, [2]float64{east, 180}, [2]float64{-180, west})
+		} else {
+			intervals = append(intervals, [2]float64{west, east})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0] < intervals[j][0] })
+
+	// The box is the complement of the largest gap between the longitudes covered
+	west := intervals[0][0]
+	east := intervals[0][1]
+	for _, interval := range intervals[1:] {
+		east = math.Max(east, interval[1])
+	}
+	largestGap := west + 360 - east
+	reach := intervals[0][1]
+	for _, interval := range intervals[1:] {
+		if gap := interval[0] - reach; gap > largestGap {
+			largestGap = gap
+			west = interval[0]
+			east = reach
+		}
+		reach = math.Max(reach, interval[1])
+	}
+	result[0] = west
+	result[len(result)/2] = east
+	return result
+}
+
+// crossesAntimeridian returns the longitude of the antimeridian crossed
+// when moving from one position to the next, or 0 if it is not crossed
+func crossesAntimeridian(from, to []float64) float64 {
+	switch delta := to[0] - from[0]; {
+	case delta > 180:
+		return -180
+	case delta < -180:
+		return 180
+	}
+	return 0
+}
+
+// antimeridianCrossing returns the position where the segment between
+// the two positions meets the antimeridian
+func antimeridianCrossing(from, to []float64, boundary float64) []float64 {
+	unwrapped := to[0] - 360
+	if boundary > 0 {
+		unwrapped = to[0] + 360
+	}
+	var fraction float64
+	if unwrapped != from[0] {
+		fraction = (boundary - from[0]) / (unwrapped - from[0])
+	}
+	result := interpolatePosition(from, to, fraction)
+	result[0] = boundary
+	return result
+}
+
+// interpolatePosition returns the position at the given fraction of the way
+// between the two positions, considering only the dimensions they share
+func interpolatePosition(from, to []float64, fraction float64) []float64 {
+	length := len(from)
+	if len(to) < length {
+		length = len(to)
+	}
+	result := make([]float64, length)
+	for inx := 0; inx < length; inx++ {
+		result[inx] = from[inx] + fraction*(to[inx]-from[inx])
+	}
+	return result
+}
+
+func samePosition(first, second []float64) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for inx := range first {
+		if first[inx] != second[inx] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitLineAntimeridian(line [][]float64) [][][]float64 {
+	if len(line) < 2 {
+		return [][][]float64{line}
+	}
+	var (
+		result  [][][]float64
+		current = [][]float64{line[0]}
+	)
+	for inx := 1; inx < len(line); inx++ {
+		prev, curr := line[inx-1], line[inx]
+		if boundary := crossesAntimeridian(prev, curr); boundary != 0 {
+			crossing := antimeridianCrossing(prev, curr, boundary)
+			if !samePosition(crossing, current[len(current)-1]) {
+				current = append(current, crossing)
+			}
+			if len(current) > 1 {
+				result = append(result, current)
+			}
+			crossing = append([]float64{}, crossing...)
+			crossing[0] = -boundary
+			current = [][]float64{crossing}
+		}
+		if !samePosition(curr, current[len(current)-1]) {
+			current = append(current, curr)
+		}
+	}
+	if len(current) > 1 {
+		result = append(result, current)
+	}
+	return result
+}
+
+// unwrapRing returns a copy of the ring whose longitudes are continuous,
+// extending beyond ±180 where needed, and whether the ring closed on itself
+func unwrapRing(ring [][]float64) ([][]float64, bool) {
+	result := make([][]float64, len(ring))
+	var offset float64
+	for inx, position := range ring {
+		if inx > 0 {
+			switch boundary := crossesAntimeridian(ring[inx-1], position); boundary {
+			case -180:
+				offset -= 360
+			case 180:
+				offset += 360
+			}
+		}
+		result[inx] = append([]float64{}, position...)
+		result[inx][0] += offset
+	}
+	return result, offset == 0
+}
+
+func splitPolygonAntimeridian(polygon [][][]float64) [][][][]float64 {
+	if len(polygon) == 0 || len(polygon[0]) < 4 {
+		return [][][][]float64{polygon}
+	}
+	rings := make([][][]float64, len(polygon))
+	for inx, ring := range polygon {
+		var closed bool
+		if rings[inx], closed = unwrapRing(ring); !closed {
+			return [][][][]float64{polygon}
+		}
+	}
+
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, position := range rings[0] {
+		minX = math.Min(minX, position[0])
+		maxX = math.Max(maxX, position[0])
+	}
+	if minX >= -180 && maxX <= 180 {
+		return [][][][]float64{polygon}
+	}
+
+	// Holes were unwrapped independently, so move them next to the shell
+	center := 0.5 * (minX + maxX)
+	for _, ring := range rings[1:] {
+		if len(ring) == 0 {
+			continue
+		}
+		shift := 360 * math.Floor((center-ring[0][0])/360+0.5)
+		for _, position := range ring {
+			position[0] += shift
+		}
+	}
+
+	var result [][][][]float64
+	for band := math.Floor((minX + 180) / 360); band <= math.Ceil((maxX-180)/360); band++ {
+		west := -180 + 360*band
+		east := 180 + 360*band
+		var piece [][][]float64
+		for inx, ring := range rings {
+			clipped := clipRingX(clipRingX(ring, west, true), east, false)
+			if len(clipped) < 4 {
+				if inx == 0 {
+					break
+				}
+				continue
+			}
+			for _, position := range clipped {
+				position[0] -= 360 * band
+			}
+			piece = append(piece, clipped)
+		}
+		if len(piece) > 0 {
+			result = append(result, piece)
+		}
+	}
+	return result
+}
+
+// clipRingX clips a closed ring against the vertical line at x, keeping
+// the side east of the line if keepEast is true or the west side otherwise
+func clipRingX(ring [][]float64, x float64, keepEast bool) [][]float64 {
+	return clipRingAxis(ring, 0, x, keepEast)
+}
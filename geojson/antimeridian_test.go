@@ -0,0 +1,122 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+func TestSplitAntimeridianLineString(t *testing.T) {
+	ls := NewLineString([][]float64{{170, 0}, {-170, 10}, {-160, 10}})
+	mls, ok := SplitAntimeridian(ls).(*MultiLineString)
+	if !ok {
+		t.Fatalf("Expected a MultiLineString, got %T", SplitAntimeridian(ls))
+	}
+	expected := `{"type":"MultiLineString","coordinates":[[[170,0],[180,5]],[[-180,5],[-170,10],[-160,10]]]}`
+	if mls.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, mls.String())
+	}
+
+	ls = NewLineString([][]float64{{10, 0}, {20, 10}})
+	if result := SplitAntimeridian(ls); result != ls {
+		t.Errorf("Expected the LineString to be returned unchanged, got %v", result)
+	}
+}
+
+func TestSplitAntimeridianPolygon(t *testing.T) {
+	polygon := NewPolygon([][][]float64{{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}}})
+	mp, ok := SplitAntimeridian(polygon).(*MultiPolygon)
+	if !ok {
+		t.Fatalf("Expected a MultiPolygon, got %T", SplitAntimeridian(polygon))
+	}
+	expected := `{"type":"MultiPolygon","coordinates":[[[[170,-10],[180,-10],[180,10],[170,10],[170,-10]]],[[[-180,-10],[-170,-10],[-170,10],[-180,10],[-180,-10]]]]}`
+	if mp.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, mp.String())
+	}
+
+	gj, err := ParseFile("test/polygon-dateline.geojson")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if mp, ok = SplitAntimeridian(gj).(*MultiPolygon); !ok {
+		t.Fatalf("Expected a MultiPolygon, got %T", SplitAntimeridian(gj))
+	}
+	if len(mp.Coordinates) != 2 {
+		t.Fatalf("Expected 2 polygons, got %v", mp.String())
+	}
+	for _, polygon := range mp.Coordinates {
+		if len(polygon) != 2 {
+			t.Errorf("Expected each side to keep part of the hole, got %v", polygon)
+		}
+		bbox, _ := NewBoundingBox(polygon)
+		if bbox.Antimeridian() || bbox[2]-bbox[0] > 10 {
+			t.Errorf("Unexpected bounding box for split polygon: %v", bbox.String())
+		}
+	}
+}
+
+func TestSplitAntimeridianFeatureCollection(t *testing.T) {
+	feature := NewFeature(NewLineString([][]float64{{170, 0}, {-170, 10}}), "crossing", map[string]interface{}{"name": "a"})
+	feature.Bbox = BoundingBox{-180, 0, 180, 10}
+	fc := NewFeatureCollection([]*Feature{feature, nil})
+	result := SplitAntimeridian(fc).(*FeatureCollection)
+	if len(result.Features) != 1 {
+		t.Fatalf("Expected the nil feature to be skipped, got %v", result.String())
+	}
+	split := result.Features[0]
+	if _, ok := split.Geometry.(*MultiLineString); !ok || split.ID != "crossing" {
+		t.Errorf("Expected a split feature, got %v", split.String())
+	}
+	if !split.Bbox.Equals(BoundingBox{170, 0, -170, 10}) {
+		t.Errorf("Expected the bounding box to be recomputed, got %v", split.Bbox)
+	}
+	split.Properties["name"] = "b"
+	if feature.PropertyString("name") != "a" {
+		t.Error("Expected the properties to be copied")
+	}
+}
+
+func TestForceWrappedBbox(t *testing.T) {
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{179, 1}), nil, nil),
+		NewFeature(NewPoint([]float64{-179, -1}), nil, nil)})
+	bbox := ForceWrappedBbox(fc)
+	if !bbox.Equals(BoundingBox{179, -1, -179, 1}) {
+		t.Errorf("Unexpected wrapped bounding box: %v", bbox)
+	}
+
+	gj, err := ParseFile("test/sample.geojson")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	bbox = ForceWrappedBbox(gj)
+	if !bbox.Equals(BoundingBox{100, 0, 105, 1}) {
+		t.Errorf("Unexpected wrapped bounding box: %v", bbox)
+	}
+
+	// The segments between positions are covered, not just the positions
+	ls := NewLineString([][]float64{{-170, 0}, {-60, 0}, {60, 0}, {170, 0}})
+	if bbox = ForceWrappedBbox(ls); !bbox.Equals(BoundingBox{-170, 0, 170, 0}) {
+		t.Errorf("Expected the line's segments to be covered, got %v", bbox)
+	}
+	ls = NewLineString([][]float64{{170, 0}, {-170, 0}, {-160, 5}})
+	if bbox = ForceWrappedBbox(ls); !bbox.Equals(BoundingBox{170, 0, -160, 5}) {
+		t.Errorf("Expected a box across the antimeridian, got %v", bbox)
+	}
+
+	if bbox = ForceWrappedBbox(NewFeatureCollection(nil)); len(bbox) != 0 {
+		t.Errorf("Expected an empty bounding box, got %v", bbox)
+	}
+}
@@ -18,6 +18,7 @@ package geojson
 
 import (
 	"errors"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -76,9 +77,11 @@ func NewBoundingBox(input interface{}) (BoundingBox, error) {
 				inputType[2]}
 		}
 	case [][]float64:
+		// Positions within a single line or ring are not expected
+		// to cross the antimeridian (RFC 7946 section 3.1.9)
 		for _, curr := range inputType {
 			if bbox2, err = NewBoundingBox(curr); err == nil {
-				result = mergeBboxes(result, bbox2)
+				result = extendBbox(result, bbox2)
 			} else {
 				return result, err
 			}
@@ -108,6 +111,8 @@ func NewBoundingBox(input interface{}) (BoundingBox, error) {
 	return result, result.Valid()
 }
 
+// mergeBboxes returns the union of the two bounding boxes,
+// allowing the result to cross the antimeridian
 func mergeBboxes(first, second BoundingBox) BoundingBox {
 	length := len(first)
 	if length == 0 {
@@ -117,29 +122,25 @@ func mergeBboxes(first, second BoundingBox) BoundingBox {
 	if length != len(second) {
 		return first
 	}
+	return first.Union(second)
+}
 
-	// For X, we must consider the antimeridian case
-	if (first[0] == -180) && (second[length/2] == 180) {
-		first[0] = second[0]
-	} else if (first[length/2] == 180) && (second[0] == -180) {
-		first[length/2] = second[length/2]
-	} else {
-		if second[0] < first[0] {
-			first[0] = second[0]
-		}
-		if second[length/2] > first[length/2] {
-			first[length/2] = second[length/2]
-		}
+// extendBbox grows the first bounding box to include the second
+// without considering the antimeridian
+func extendBbox(first, second BoundingBox) BoundingBox {
+	length := len(first)
+	if length == 0 {
+		return second
 	}
-
-	// Consider the minimum values
-	for inx := 1; inx < length/2; inx++ {
+	if length != len(second) {
+		return first
+	}
+	for inx := 0; inx < length/2; inx++ {
 		if second[inx] < first[inx] {
 			first[inx] = second[inx]
 		}
 	}
-	// Consider the maximum values
-	for inx := 1 + length/2; inx < length; inx++ {
+	for inx := length / 2; inx < length; inx++ {
 		if second[inx] > first[inx] {
 			first[inx] = second[inx]
 		}
@@ -147,6 +148,196 @@ func mergeBboxes(first, second BoundingBox) BoundingBox {
 	return first
 }
 
+// longitudeSpan returns the western and eastern longitudes of the bounding box
+// and the number of degrees between them, which is 360 for a global box
+func (bb BoundingBox) longitudeSpan() (float64, float64, float64) {
+	west := bb[0]
+	east := bb[len(bb)/2]
+	return west, east, spanWidth(west, east)
+}
+
+// spanWidth returns the number of degrees from west to east
+func spanWidth(west, east float64) float64 {
+	if east < west {
+		return east - west + 360
+	}
+	return east - west
+}
+
+// eastOf returns the number of degrees one must travel east
+// from the first longitude to reach the second
+func eastOf(from, to float64) float64 {
+	result := math.Mod(to-from, 360)
+	if result < 0 {
+		result += 360
+	}
+	return result
+}
+
+// arcContains returns true if the longitude range starting at west2
+// lies within the one starting at west1
+func arcContains(west1, width1, west2, width2 float64) bool {
+	if width1 >= 360 {
+		return true
+	}
+	return eastOf(west1, west2)+width2 <= width1
+}
+
+// Union returns the smallest bounding box containing both bounding boxes.
+// Of the two ways the longitudes can be joined, the narrower one is chosen,
+// so the result crosses the antimeridian when that is shorter.
+func (bb BoundingBox) Union(test BoundingBox) BoundingBox {
+	if len(bb) == 0 {
+		return append(BoundingBox{}, test...)
+	}
+	result := append(BoundingBox{}, bb...)
+	if len(test) != len(bb) {
+		return result
+	}
+	dimensions := len(bb) / 2
+	for inx := 1; inx < dimensions; inx++ {
+		result[inx] = math.Min(bb[inx], test[inx])
+		result[inx+dimensions] = math.Max(bb[inx+dimensions], test[inx+dimensions])
+	}
+
+	west1, east1, width1 := bb.longitudeSpan()
+	west2, east2, width2 := test.longitudeSpan()
+	switch {
+	case width1 >= 360 || width2 >= 360:
+		result[0], result[dimensions] = -180, 180
+	case arcContains(west1, width1, west2, width2):
+		result[0], result[dimensions] = west1, east1
+	case arcContains(west2, width2, west1, width1):
+		result[0], result[dimensions] = west2, east2
+	default:
+		// Either start at the first box and end at the second or vice versa
+		best := math.Inf(1)
+		for _, candidate := range [][2]float64{{west1, east2}, {west2, east1}} {
+			span := spanWidth(candidate[0], candidate[1])
+			if span < best && arcContains(candidate[0], span, west1, width1) && arcContains(candidate[0], span, west2, width2) {
+				best = span
+				result[0], result[dimensions] = candidate[0], candidate[1]
+			}
+		}
+		if math.IsInf(best, 1) {
+			result[0], result[dimensions] = -180, 180
+		}
+	}
+	return result
+}
+
+// Intersection returns the bounding box common to both bounding boxes,
+// or an empty bounding box if they do not intersect.
+// If the longitudes meet in two separate ranges, as can happen when
+// both boxes wrap far around the globe, the wider range is returned.
+func (bb BoundingBox) Intersection(test BoundingBox) BoundingBox {
+	if len(bb) == 0 || len(bb) != len(test) {
+		return BoundingBox{}
+	}
+	dimensions := len(bb) / 2
+	result := make(BoundingBox, len(bb))
+	for inx := 1; inx < dimensions; inx++ {
+		result[inx] = math.Max(bb[inx], test[inx])
+		result[inx+dimensions] = math.Min(bb[inx+dimensions], test[inx+dimensions])
+		if result[inx] > result[inx+dimensions] {
+			return BoundingBox{}
+		}
+	}
+
+	// Work in degrees east of the first box's western edge
+	west1, east1, width1 := bb.longitudeSpan()
+	west2, east2, width2 := test.longitudeSpan()
+	switch {
+	case width2 >= 360:
+		result[0], result[dimensions] = west1, east1
+		return result
+	case width1 >= 360:
+		result[0], result[dimensions] = west2, east2
+		return result
+	}
+	offset := eastOf(west1, west2)
+	found := false
+	var low, high float64
+	for _, start := range []float64{offset, offset - 360} {
+		currLow := math.Max(0, start)
+		currHigh := math.Min(width1, start+width2)
+		if currLow <= currHigh && (!found || currHigh-currLow > high-low) {
+			found = true
+			low, high = currLow, currHigh
+		}
+	}
+	if !found {
+		return BoundingBox{}
+	}
+	result[0] = normalizeLongitude(west1 + low)
+	result[dimensions] = normalizeLongitude(west1 + high)
+	if result[0] == 180 && high > low {
+		result[0] = -180
+	}
+	if result[dimensions] == -180 && high > low {
+		result[dimensions] = 180
+	}
+	return result
+}
+
+// normalizeLongitude returns the equivalent longitude between -180 and 180
+func normalizeLongitude(longitude float64) float64 {
+	for longitude > 180 {
+		longitude -= 360
+	}
+	for longitude < -180 {
+		longitude += 360
+	}
+	return longitude
+}
+
+// ContainsPoint returns true if the point lies within or on the edge
+// of the bounding box, respecting the antimeridian
+func (bb BoundingBox) ContainsPoint(point *Point) bool {
+	if point == nil || len(bb) == 0 {
+		return false
+	}
+	pointBbox, err := NewBoundingBox(point.Coordinates)
+	if err != nil || len(pointBbox) == 0 {
+		return false
+	}
+	if len(pointBbox) > len(bb) {
+		pointBbox = BoundingBox{pointBbox[0], pointBbox[1], pointBbox[0], pointBbox[1]}
+	}
+	return bb.ContainsBbox(pointBbox)
+}
+
+// ContainsBbox returns true if the test bounding box lies entirely within
+// this one, respecting the antimeridian
+func (bb BoundingBox) ContainsBbox(test BoundingBox) bool {
+	bblen := len(bb)
+	if (bblen == 0) || (len(test) == 0) {
+		return false
+	}
+	dimensions := bblen / 2
+	testDimensions := len(test) / 2
+	// Only compare the dimensions both boxes have
+	if testDimensions < dimensions {
+		dimensions = testDimensions
+	}
+	for inx := 1; inx < dimensions; inx++ {
+		if test[inx] < bb[inx] || test[inx+testDimensions] > bb[inx+bblen/2] {
+			return false
+		}
+	}
+	west1, _, width1 := bb.longitudeSpan()
+	west2, _, width2 := test.longitudeSpan()
+	return arcContains(west1, width1, west2, width2)
+}
+
+// clone returns a copy of the BoundingBox that shares no storage with it
+func (bb BoundingBox) clone() BoundingBox {
+	if bb == nil {
+		return nil
+	}
+	return append(BoundingBox{}, bb...)
+}
+
 // Equals returns true if all points in the bounding boxes are equal
 func (bb BoundingBox) Equals(test BoundingBox) bool {
 	bblen := len(bb)
@@ -258,3 +258,76 @@ func TestBboxParseNoSideEffects(t *testing.T) {
 	NewBoundingBox(passedInData)
 	checkInputUnchanged(originalData, passedInData)
 }
+
+func TestBboxUnion(t *testing.T) {
+	tests := []struct {
+		first, second, expected BoundingBox
+	}{
+		{BoundingBox{10, 10, 20, 20}, BoundingBox{30, 0, 40, 15}, BoundingBox{10, 0, 40, 20}},
+		{BoundingBox{178, 0, 179, 1}, BoundingBox{-179, -1, -178, 0}, BoundingBox{178, -1, -178, 1}},
+		{BoundingBox{-179, -1, -178, 0}, BoundingBox{178, 0, 179, 1}, BoundingBox{178, -1, -178, 1}},
+		{BoundingBox{170, 0, -170, 1}, BoundingBox{-175, 0, -160, 1}, BoundingBox{170, 0, -160, 1}},
+		{BoundingBox{170, 0, -170, 1}, BoundingBox{175, 0, -175, 1}, BoundingBox{170, 0, -170, 1}},
+		{BoundingBox{-180, 0, 180, 1}, BoundingBox{175, 0, -175, 1}, BoundingBox{-180, 0, 180, 1}},
+		{BoundingBox{}, BoundingBox{1, 2, 3, 4}, BoundingBox{1, 2, 3, 4}},
+	}
+	for _, test := range tests {
+		if result := test.first.Union(test.second); !result.Equals(test.expected) {
+			t.Errorf("Union of %v and %v: expected %v, got %v", test.first, test.second, test.expected, result)
+		}
+	}
+
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{179, 1}), nil, nil),
+		NewFeature(NewPoint([]float64{-179, -1}), nil, nil)})
+	if bbox := fc.ForceBbox(); !bbox.Antimeridian() {
+		t.Errorf("Expected the Feature Collection bounding box to cross the antimeridian: %v", bbox)
+	}
+}
+
+func TestBboxIntersection(t *testing.T) {
+	tests := []struct {
+		first, second, expected BoundingBox
+	}{
+		{BoundingBox{10, 10, 20, 20}, BoundingBox{15, 0, 40, 15}, BoundingBox{15, 10, 20, 15}},
+		{BoundingBox{10, 10, 20, 20}, BoundingBox{30, 0, 40, 15}, BoundingBox{}},
+		{BoundingBox{10, 10, 20, 20}, BoundingBox{15, 30, 40, 40}, BoundingBox{}},
+		{BoundingBox{170, 0, -170, 10}, BoundingBox{-175, 5, -160, 20}, BoundingBox{-175, 5, -170, 10}},
+		{BoundingBox{170, 0, -170, 10}, BoundingBox{160, 0, 175, 10}, BoundingBox{170, 0, 175, 10}},
+		{BoundingBox{170, 0, -170, 10}, BoundingBox{175, 0, -175, 10}, BoundingBox{175, 0, -175, 10}},
+		{BoundingBox{170, 0, -170, 10}, BoundingBox{-180, 0, 180, 10}, BoundingBox{170, 0, -170, 10}},
+	}
+	for _, test := range tests {
+		if result := test.first.Intersection(test.second); !result.Equals(test.expected) {
+			t.Errorf("Intersection of %v and %v: expected %v, got %v", test.first, test.second, test.expected, result)
+		}
+	}
+}
+
+func TestBboxContains(t *testing.T) {
+	bbox := BoundingBox{170, -10, -170, 10}
+	if !bbox.ContainsPoint(NewPoint([]float64{180, 0})) {
+		t.Error("Expected the bounding box to contain a point on the antimeridian")
+	}
+	if !bbox.ContainsPoint(NewPoint([]float64{-175, 5, 100})) {
+		t.Error("Expected the bounding box to contain a point west of the antimeridian")
+	}
+	if bbox.ContainsPoint(NewPoint([]float64{0, 0})) {
+		t.Error("Did not expect the bounding box to contain a point at the prime meridian")
+	}
+	if bbox.ContainsPoint(nil) {
+		t.Error("Did not expect the bounding box to contain a nil point")
+	}
+	if !bbox.ContainsBbox(BoundingBox{175, -5, -175, 5}) {
+		t.Error("Expected the bounding box to contain a smaller box across the antimeridian")
+	}
+	if !bbox.ContainsBbox(BoundingBox{171, -5, 172, 5}) {
+		t.Error("Expected the bounding box to contain a smaller box east of the antimeridian")
+	}
+	if bbox.ContainsBbox(BoundingBox{160, -5, 175, 5}) {
+		t.Error("Did not expect the bounding box to contain a box extending beyond it")
+	}
+	if (BoundingBox{10, 10, 20, 20}).ContainsBbox(BoundingBox{175, 12, -175, 15}) {
+		t.Error("Did not expect the bounding box to contain a box across the antimeridian")
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+// This file clips geometries to axis-aligned rectangles in planar coordinates.
+// Rings are clipped with the Sutherland-Hodgman algorithm, so concave polygons
+// may gain zero-width edges along the rectangle.
+
+// clipGeometry returns the part of a geometry inside the rectangle, or nil if
+// nothing remains. Lines and polygons that are cut into several pieces become
+// MultiLineStrings and MultiPolygons.
+func clipGeometry(geometry interface{}, rect rtreeRect) interface{} {
+	switch typed := geometry.(type) {
+	case *Point:
+		if typed != nil && len(typed.Coordinates) >= 2 && rect.containsPosition(typed.Coordinates[0], typed.Coordinates[1]) {
+			return NewPoint(clone1(typed.Coordinates))
+		}
+	case *MultiPoint:
+		var positions [][]float64
+		for _, position := range typed.Coordinates {
+			if len(position) >= 2 && rect.containsPosition(position[0], position[1]) {
+				positions = append(positions, clone1(position))
+			}
+		}
+		if len(positions) > 0 {
+			return NewMultiPoint(positions)
+		}
+	case *LineString:
+		return linesGeometry(clipLine(typed.Coordinates, rect))
+	case *MultiLineString:
+		var lines [][][]float64
+		for _, line := range typed.Coordinates {
+			lines = append(lines, clipLine(line, rect)...)
+		}
+		return linesGeometry(lines)
+	case *Polygon:
+		return polygonsGeometry(clipPolygon(typed.Coordinates, rect))
+	case *MultiPolygon:
+		var polygons [][][][]float64
+		for _, polygon := range typed.Coordinates {
+			polygons = append(polygons, clipPolygon(polygon, rect)...)
+		}
+		return polygonsGeometry(polygons)
+	case *GeometryCollection:
+		var geometries []interface{}
+		for _, curr := range typed.Geometries {
+			if clipped := clipGeometry(curr, rect); clipped != nil {
+				geometries = append(geometries, clipped)
+			}
+		}
+		if len(geometries) > 0 {
+			return NewGeometryCollection(geometries)
+		}
+	}
+	return nil
+}
+
+func linesGeometry(lines [][][]float64) interface{} {
+	switch len(lines) {
+	case 0:
+		return nil
+	case 1:
+		return NewLineString(lines[0])
+	}
+	return NewMultiLineString(lines)
+}
+
+func polygonsGeometry(polygons [][][][]float64) interface{} {
+	switch len(polygons) {
+	case 0:
+		return nil
+	case 1:
+		return NewPolygon(polygons[0])
+	}
+	return NewMultiPolygon(polygons)
+}
+
+// clipLine returns the pieces of a line inside the rectangle
+func clipLine(line [][]float64, rect rtreeRect) [][][]float64 {
+	if len(line) == 1 {
+		if rect.containsPosition(line[0][0], line[0][1]) {
+			return [][][]float64{clone2(line)}
+		}
+		return nil
+	}
+	lines := [][][]float64{line}
+	for _, edge := range rectEdges(rect) {
+		var pieces [][][]float64
+		for _, curr := range lines {
+			pieces = append(pieces, clipLineAxis(curr, edge.axis, edge.value, edge.keepGreater)...)
+		}
+		lines = pieces
+	}
+	return lines
+}
+
+// clipPolygon returns the polygon clipped to the rectangle, or nothing if its
+// exterior ring lies outside. Holes lying outside are dropped.
+func clipPolygon(polygon [][][]float64, rect rtreeRect) [][][][]float64 {
+	var result [][][]float64
+	for inx, ring := range polygon {
+		for _, edge := range rectEdges(rect) {
+			ring = clipRingAxis(ring, edge.axis, edge.value, edge.keepGreater)
+		}
+		if len(ring) < 4 {
+			if inx == 0 {
+				return nil
+			}
+			continue
+		}
+		result = append(result, ring)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return [][][][]float64{result}
+}
+
+type clipEdge struct {
+	axis        int
+	value       float64
+	keepGreater bool
+}
+
+func rectEdges(rect rtreeRect) []clipEdge {
+	return []clipEdge{{0, rect[0], true}, {0, rect[2], false}, {1, rect[1], true}, {1, rect[3], false}}
+}
+
+// clipIntersect returns the position where a segment crosses value on the axis
+func clipIntersect(from, to []float64, axis int, value float64) []float64 {
+	result := interpolatePosition(from, to, (value-from[axis])/(to[axis]-from[axis]))
+	result[axis] = value
+	return result
+}
+
+// clipRingAxis clips a closed ring against the line where the axis has the value,
+// keeping the side with greater values if keepGreater is true or the other side otherwise
+func clipRingAxis(ring [][]float64, axis int, value float64, keepGreater bool) [][]float64 {
+	if len(ring) < 4 {
+		return nil
+	}
+	inside := func(position []float64) bool {
+		if keepGreater {
+			return position[axis] >= value
+		}
+		return position[axis] <= value
+	}
+
+	var result [][]float64
+	open := ring[:len(ring)-1]
+	prev := open[len(open)-1]
+	for _, curr := range open {
+		if inside(curr) {
+			if !inside(prev) {
+				result = append(result, clipIntersect(prev, curr, axis, value))
+			}
+			result = append(result, append([]float64{}, curr...))
+		} else if inside(prev) {
+			result = append(result, clipIntersect(prev, curr, axis, value))
+		}
+		prev = curr
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return append(result, append([]float64{}, result[0]...))
+}
+
+// clipLineAxis returns the pieces of a line on one side of the line
+// where the axis has the value
+func clipLineAxis(line [][]float64, axis int, value float64, keepGreater bool) [][][]float64 {
+	inside := func(position []float64) bool {
+		if keepGreater {
+			return position[axis] >= value
+		}
+		return position[axis] <= value
+	}
+	var (
+		result [][][]float64
+		piece  [][]float64
+	)
+	for inx, curr := range line {
+		if inx > 0 {
+			prev := line[inx-1]
+			if inside(prev) != inside(curr) {
+				crossing := clipIntersect(prev, curr, axis, value)
+				if inside(curr) {
+					piece = [][]float64{crossing}
+				} else {
+					if !samePosition(piece[len(piece)-1], crossing) {
+						piece = append(piece, crossing)
+					}
+					if len(piece) > 1 {
+						result = append(result, piece)
+					}
+					piece = nil
+				}
+			}
+		}
+		if inside(curr) && (len(piece) == 0 || !samePosition(piece[len(piece)-1], curr)) {
+			piece = append(piece, clone1(curr))
+		}
+	}
+	if len(piece) > 1 {
+		result = append(result, piece)
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCloneGeometries(t *testing.T) {
+	for _, fileName := range inputFiles {
+		gj, err := ParseFile(fileName)
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+		var clone interface{}
+		switch typedGJ := gj.(type) {
+		case *Feature:
+			clone = typedGJ.Clone()
+		case *FeatureCollection:
+			clone = typedGJ.Clone()
+		default:
+			clone = cloneGeometry(gj)
+		}
+		original := gj.(fmt.Stringer).String()
+		if clone.(fmt.Stringer).String() != original {
+			t.Errorf("Clone of %v differs: %v", fileName, clone)
+		}
+		EachCoordinate(clone, func(position []float64) {
+			position[0] = 999
+		})
+		if gj.(fmt.Stringer).String() != original {
+			t.Errorf("Modifying the clone of %v modified the original", fileName)
+		}
+	}
+	var point *Point
+	if cloneGeometry(point) != point {
+		t.Error("Expected a nil Point to clone as nil")
+	}
+}
+
+func TestCloneFeature(t *testing.T) {
+	nested := map[string]interface{}{"list": []interface{}{1.0, map[string]interface{}{"a": "b"}}}
+	strings := [2]string{"big", "bad"}
+	feature := NewFeature(NewPoint([]float64{1, 2}), "id", map[string]interface{}{
+		"nested":  nested,
+		"slice":   []string{"x", "y"},
+		"array":   strings,
+		"pointer": &[]string{"p"}})
+	feature.Bbox = BoundingBox{1, 2, 1, 2}
+	clone := feature.Clone()
+	if clone.String() != feature.String() {
+		t.Errorf("Clone differs: %v", clone.String())
+	}
+	clone.Properties["nested"].(map[string]interface{})["list"].([]interface{})[1].(map[string]interface{})["a"] = "c"
+	clone.Properties["slice"].([]string)[0] = "z"
+	(*clone.Properties["pointer"].(*[]string))[0] = "q"
+	clone.Bbox[0] = 5
+	clone.Geometry.(*Point).Coordinates[0] = 5
+	if nested["list"].([]interface{})[1].(map[string]interface{})["a"] != "b" {
+		t.Error("Modifying the clone's nested properties modified the original")
+	}
+	if feature.Properties["slice"].([]string)[0] != "x" {
+		t.Error("Modifying the clone's slice property modified the original")
+	}
+	if (*feature.Properties["pointer"].(*[]string))[0] != "p" {
+		t.Error("Modifying the clone's pointer property modified the original")
+	}
+	if feature.Bbox[0] != 1 || feature.Geometry.(*Point).Coordinates[0] != 1 {
+		t.Error("Modifying the clone's geometry modified the original")
+	}
+
+	fc := NewFeatureCollection([]*Feature{feature})
+	fcClone := fc.Clone()
+	fcClone.Features[0].Properties["new"] = true
+	if _, ok := feature.Properties["new"]; ok {
+		t.Error("Modifying the cloned Feature Collection modified the original")
+	}
+
+	var nilFeature *Feature
+	var nilCollection *FeatureCollection
+	if nilFeature.Clone() != nil || nilCollection.Clone() != nil {
+		t.Error("Expected nil clones of nil objects")
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "fmt"
+
+// EachCoordinate calls the function provided with every position
+// in the GeoJSON object, in document order.
+// The positions passed are the object's own, so changes made to them
+// will be reflected in the object.
+func EachCoordinate(gjObject interface{}, fn func(position []float64)) {
+	switch typedGJ := gjObject.(type) {
+	case *Point:
+		if typedGJ.Coordinates != nil {
+			fn(typedGJ.Coordinates)
+		}
+	case *LineString:
+		each2(typedGJ.Coordinates, fn)
+	case *MultiPoint:
+		each2(typedGJ.Coordinates, fn)
+	case *Polygon:
+		for _, c3 := range typedGJ.Coordinates {
+			each2(c3, fn)
+		}
+	case *MultiLineString:
+		for _, c3 := range typedGJ.Coordinates {
+			each2(c3, fn)
+		}
+	case *MultiPolygon:
+		for _, c4 := range typedGJ.Coordinates {
+			for _, c3 := range c4 {
+				each2(c3, fn)
+			}
+		}
+	case *GeometryCollection:
+		for _, geometry := range typedGJ.Geometries {
+			EachCoordinate(geometry, fn)
+		}
+	case *Feature:
+		EachCoordinate(typedGJ.Geometry, fn)
+	case *FeatureCollection:
+		for _, feature := range typedGJ.Features {
+			EachCoordinate(feature, fn)
+		}
+	}
+}
+
+func each2(input [][]float64, fn func(position []float64)) {
+	for _, position := range input {
+		fn(position)
+	}
+}
+
+// MapCoordinates returns a deep copy of the GeoJSON object
+// with every position replaced by the result of the function provided.
+// The function receives a copy of each position, so it may modify and return it.
+// Any bbox members are dropped from the result since they may no longer be accurate;
+// use ForceBbox to compute new ones.
+// A nil object is returned as it is, and an error is returned
+// if the object is not a GeoJSON object.
+func MapCoordinates(gjObject interface{}, fn func(position []float64) []float64) (interface{}, error) {
+	return mapPositions(gjObject, func(position []float64) ([]float64, error) {
+		return fn(position), nil
+	}, nil)
+}
+
+// mapPositions returns a copy of the GeoJSON object with each position
+// replaced by the result of the function provided.
+// Bounding boxes that were present are either recomputed from the new object
+// with the bbox function provided or, if it is nil, dropped,
+// since they would no longer be accurate.
+// Nil objects, including nil pointers, are returned as they are.
+func mapPositions(gjObject interface{}, fn func([]float64) ([]float64, error), bbox func(interface{}) BoundingBox) (interface{}, error) {
+	var err error
+	map1 := func(input []float64) ([]float64, error) {
+		if input == nil {
+			return nil, nil
+		}
+		return fn(append([]float64{}, input...))
+	}
+	map2 := func(input [][]float64) ([][]float64, error) {
+		if input == nil {
+			return nil, nil
+		}
+		result := make([][]float64, len(input))
+		for inx, curr := range input {
+			if result[inx], err = map1(curr); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	map3 := func(input [][][]float64) ([][][]float64, error) {
+		if input == nil {
+			return nil, nil
+		}
+		result := make([][][]float64, len(input))
+		for inx, curr := range input {
+			if result[inx], err = map2(curr); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	map4 := func(input [][][][]float64) ([][][][]float64, error) {
+		if input == nil {
+			return nil, nil
+		}
+		result := make([][][][]float64, len(input))
+		for inx, curr := range input {
+			if result[inx], err = map3(curr); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	switch typedGJ := gjObject.(type) {
+	case *Point:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		result := &Point{Type: POINT}
+		if result.Coordinates, err = map1(typedGJ.Coordinates); err != nil {
+			return nil, err
+		}
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *LineString:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		result := &LineString{Type: LINESTRING}
+		if result.Coordinates, err = map2(typedGJ.Coordinates); err != nil {
+			return nil, err
+		}
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *Polygon:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		result := &Polygon{Type: POLYGON}
+		if result.Coordinates, err = map3(typedGJ.Coordinates); err != nil {
+			return nil, err
+		}
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *MultiPoint:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		result := &MultiPoint{Type: MULTIPOINT}
+		if result.Coordinates, err = map2(typedGJ.Coordinates); err != nil {
+			return nil, err
+		}
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *MultiLineString:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		result := &MultiLineString{Type: MULTILINESTRING}
+		if result.Coordinates, err = map3(typedGJ.Coordinates); err != nil {
+			return nil, err
+		}
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *MultiPolygon:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		result := &MultiPolygon{Type: MULTIPOLYGON}
+		if result.Coordinates, err = map4(typedGJ.Coordinates); err != nil {
+			return nil, err
+		}
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *GeometryCollection:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		geometries := make([]interface{}, len(typedGJ.Geometries))
+		for inx, geometry := range typedGJ.Geometries {
+			if geometries[inx], err = mapPositions(geometry, fn, bbox); err != nil {
+				return nil, err
+			}
+		}
+		result := NewGeometryCollection(geometries)
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *Feature:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		result := &Feature{Type: FEATURE, ID: cloneValue(typedGJ.ID), Properties: cloneProperties(typedGJ.Properties)}
+		if result.Geometry, err = mapPositions(typedGJ.Geometry, fn, bbox); err != nil {
+			return nil, err
+		}
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case *FeatureCollection:
+		if typedGJ == nil {
+			return typedGJ, nil
+		}
+		features := make([]*Feature, len(typedGJ.Features))
+		for inx, feature := range typedGJ.Features {
+			var featureIfc interface{}
+			if featureIfc, err = mapPositions(feature, fn, bbox); err != nil {
+				return nil, err
+			}
+			features[inx] = featureIfc.(*Feature)
+		}
+		result := NewFeatureCollection(features)
+		if bbox != nil && len(typedGJ.Bbox) > 0 {
+			result.Bbox = bbox(result)
+		}
+		return result, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("Cannot map the positions of %T", gjObject)
+}
+
+// planarBbox returns the bounding box of all of the object's positions
+// as a plain rectangle, for coordinates that do not wrap at the antimeridian
+func planarBbox(gjObject interface{}) BoundingBox {
+	var positions [][]float64
+	EachCoordinate(gjObject, func(position []float64) {
+		positions = append(positions, position)
+	})
+	result, _ := NewBoundingBox(positions)
+	return result
+}
+
+// geographicBbox returns the bounding box of an object in longitude and latitude,
+// which may cross the antimeridian
+func geographicBbox(gjObject interface{}) BoundingBox {
+	if bboxIfc, ok := gjObject.(BoundingBoxIfc); ok {
+		return bboxIfc.ForceBbox()
+	}
+	return BoundingBox{}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+func TestEachCoordinate(t *testing.T) {
+	for _, fileName := range inputFiles {
+		gj, err := ParseFile(fileName)
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+		count := 0
+		EachCoordinate(gj, func(position []float64) {
+			if len(position) < 2 {
+				t.Errorf("Received a short position %v from %v", position, fileName)
+			}
+			count++
+		})
+		if count == 0 {
+			t.Errorf("Did not visit any positions in %v", fileName)
+		}
+	}
+
+	gj, _ := ParseFile("test/sample.geojson")
+	count := 0
+	EachCoordinate(gj, func(position []float64) { count++ })
+	if count != 10 {
+		t.Errorf("Expected 10 positions, got %v", count)
+	}
+}
+
+func TestMapCoordinates(t *testing.T) {
+	gj, err := ParseFile("test/sample.geojson")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	fc := gj.(*FeatureCollection)
+	fc.Bbox = fc.ForceBbox()
+	original := fc.String()
+	gj, err = MapCoordinates(fc, func(position []float64) []float64 {
+		position[0] = -position[0]
+		return position
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := gj.(*FeatureCollection)
+	if fc.String() != original {
+		t.Errorf("MapCoordinates modified its input: %v", fc.String())
+	}
+	expected := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-102,0.5]},"properties":{"prop0":"value0"}},{"type":"Feature","geometry":{"type":"LineString","coordinates":[[-102,0],[-103,1],[-104,0],[-105,1]]},"properties":{"prop0":"value0","prop1":0}},{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[-100,0],[-101,0],[-101,1],[-100,1],[-100,0]]]},"properties":{"prop0":"value0","prop1":{"this":"that"}}}]}`
+	if result.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, result.String())
+	}
+
+	gc := NewGeometryCollection([]interface{}{NewPoint([]float64{1, 2}), NewMultiPolygon([][][][]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}})})
+	gc.Bbox = gc.ForceBbox()
+	gj, err = MapCoordinates(gc, func(position []float64) []float64 {
+		return []float64{position[0] + 1, position[1] + 1}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapped := gj.(*GeometryCollection)
+	if len(mapped.Bbox) != 0 {
+		t.Errorf("Expected the stale bounding box to be dropped, got %v", mapped.Bbox)
+	}
+	if bbox := mapped.ForceBbox(); !bbox.Equals(BoundingBox{1, 1, 2, 3}) {
+		t.Errorf("Unexpected bounding box after mapping: %v", bbox)
+	}
+	if gj, err = MapCoordinates("not geojson", func(position []float64) []float64 { return position }); err == nil || gj != nil {
+		t.Errorf("Expected an error for an unsupported object, got %v", gj)
+	}
+
+	// Nil pointers are returned as they are rather than dereferenced
+	identity := func(position []float64) []float64 { return position }
+	for _, input := range []interface{}{(*Feature)(nil), (*Polygon)(nil), (*GeometryCollection)(nil), (*FeatureCollection)(nil)} {
+		if gj, err = MapCoordinates(input, identity); err != nil || gj != input {
+			t.Errorf("Expected %T nil to be returned, got %v, %v", input, gj, err)
+		}
+	}
+	withNil := NewFeatureCollection([]*Feature{nil, NewFeature(nil, nil, nil)})
+	if gj, err = MapCoordinates(withNil, identity); err != nil || gj.(*FeatureCollection).Features[0] != nil {
+		t.Errorf("Expected a nil feature to be kept, got %v, %v", gj, err)
+	}
+}
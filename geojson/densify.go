@@ -0,0 +1,76 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "math"
+
+// Densify returns a copy of a LineString, Polygon, MultiLineString or MultiPolygon
+// with positions inserted so that no segment is longer than maxSegmentLength.
+// In Planar mode the length is in coordinate units and new positions lie on
+// straight lines; in Geodesic mode the length is in metres and new positions
+// lie along great circles.
+// Other objects, or a maxSegmentLength that is not positive, yield an unchanged copy.
+func Densify(geometry interface{}, maxSegmentLength float64, mode DistanceMode) interface{} {
+	if maxSegmentLength <= 0 {
+		return cloneGeometry(geometry)
+	}
+	switch typed := geometry.(type) {
+	case *LineString:
+		return NewLineString(densifyLine(typed.Coordinates, maxSegmentLength, mode))
+	case *Polygon:
+		return NewPolygon(densifyLines(typed.Coordinates, maxSegmentLength, mode))
+	case *MultiLineString:
+		return NewMultiLineString(densifyLines(typed.Coordinates, maxSegmentLength, mode))
+	case *MultiPolygon:
+		var coordinates [][][][]float64
+		if typed.Coordinates != nil {
+			coordinates = make([][][][]float64, len(typed.Coordinates))
+		}
+		for inx, polygon := range typed.Coordinates {
+			coordinates[inx] = densifyLines(polygon, maxSegmentLength, mode)
+		}
+		return NewMultiPolygon(coordinates)
+	}
+	return cloneGeometry(geometry)
+}
+
+func densifyLines(lines [][][]float64, maxSegmentLength float64, mode DistanceMode) [][][]float64 {
+	if lines == nil {
+		return nil
+	}
+	result := make([][][]float64, len(lines))
+	for inx, line := range lines {
+		result[inx] = densifyLine(line, maxSegmentLength, mode)
+	}
+	return result
+}
+
+func densifyLine(line [][]float64, maxSegmentLength float64, mode DistanceMode) [][]float64 {
+	if len(line) == 0 {
+		return clone2(line)
+	}
+	result := [][]float64{clone1(line[0])}
+	for inx := 1; inx < len(line); inx++ {
+		prev, curr := line[inx-1], line[inx]
+		segments := math.Ceil(positionDistance(prev, curr, mode) / maxSegmentLength)
+		for step := 1.0; step < segments; step++ {
+			result = append(result, interpolate(prev, curr, step/segments, mode))
+		}
+		result = append(result, clone1(curr))
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDensifyPlanar(t *testing.T) {
+	ls := NewLineString([][]float64{{0, 0}, {10, 0, 5}, {10, 1}})
+	result := Densify(ls, 2.5, Planar).(*LineString)
+	expected := `{"type":"LineString","coordinates":[[0,0],[2.5,0],[5,0],[7.5,0],[10,0,5],[10,1]]}`
+	if result.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, result.String())
+	}
+
+	polygon := NewPolygon([][][]float64{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}})
+	densePolygon := Densify(polygon, 2, Planar).(*Polygon)
+	if len(densePolygon.Coordinates[0]) != 8 {
+		t.Errorf("Expected 8 positions, got %v", densePolygon.String())
+	}
+	if len(polygon.Coordinates[0]) != 4 {
+		t.Error("Densify modified its input")
+	}
+
+	mp := NewMultiPolygon([][][][]float64{polygon.Coordinates, polygon.Coordinates})
+	if result := Densify(mp, 2, Planar).(*MultiPolygon); len(result.Coordinates[1][0]) != 8 {
+		t.Errorf("Unexpected MultiPolygon result: %v", result.String())
+	}
+	if result := Densify(ls, 0, Planar).(*LineString); !Equals(result, ls) {
+		t.Errorf("Expected an unchanged copy, got %v", result.String())
+	}
+}
+
+func TestDensifyGeodesic(t *testing.T) {
+	// New York to London
+	mls := NewMultiLineString([][][]float64{{{-74.006, 40.7128}, {-0.1278, 51.5074}}})
+	result := Densify(mls, 1000000, Geodesic).(*MultiLineString)
+	line := result.Coordinates[0]
+	if len(line) != 7 {
+		t.Fatalf("Expected 7 positions, got %v", result.String())
+	}
+	for inx := 1; inx < len(line); inx++ {
+		testClose(t, "Segment length", 5570222.0/6, haversineDistance(line[inx-1], line[inx]), 1000)
+	}
+	// The great circle bulges north of both endpoints
+	if line[3][1] < 51.5074 {
+		t.Errorf("Expected the midpoint to be north of London, got %v", line[3])
+	}
+
+	// Crossing the antimeridian
+	ls := NewLineString([][]float64{{170, 0}, {-170, 0}})
+	line = Densify(ls, 1200000, Geodesic).(*LineString).Coordinates
+	if len(line) != 3 {
+		t.Fatalf("Expected 3 positions, got %v", line)
+	}
+	testClose(t, "Antimeridian midpoint", 180, math.Abs(line[1][0]), 1e-9)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "math"
+
+// Distance returns the minimum distance between two geometries, together with
+// the point on each geometry at which it occurs. The distance is zero if the
+// geometries intersect, including when one lies inside a polygon of the other.
+// Features and FeatureCollections are measured by their geometries.
+// In Geodesic mode the result is in metres, but intersection and the nearest
+// points on segments are found along great circles only approximately.
+// If either geometry has no positions the distance is NaN and the points are nil.
+func Distance(a, b interface{}, mode DistanceMode) (float64, *Point, *Point) {
+	partsA, partsB := decompose(a), decompose(b)
+	if partsA.empty() || partsB.empty() {
+		return math.NaN(), nil, nil
+	}
+	if position := partsA.intersection(partsB); position != nil {
+		return 0, NewPoint(clone1(position)), NewPoint(clone1(position))
+	}
+
+	var (
+		best     = math.Inf(1)
+		nearestA []float64
+		nearestB []float64
+		consider = func(positionA, positionB []float64, distance float64) {
+			if distance < best {
+				best, nearestA, nearestB = distance, positionA, positionB
+			}
+		}
+	)
+	for _, pointA := range partsA.points {
+		for _, pointB := range partsB.points {
+			consider(pointA, pointB, positionDistance(pointA, pointB, mode))
+		}
+		for _, segment := range partsB.segments {
+			position, _, distance := nearestOnSegment(pointA, segment[0], segment[1], mode)
+			consider(pointA, position, distance)
+		}
+	}
+	for _, segment := range partsA.segments {
+		for _, pointB := range partsB.points {
+			position, _, distance := nearestOnSegment(pointB, segment[0], segment[1], mode)
+			consider(position, pointB, distance)
+		}
+		// Segments that do not cross are nearest at an endpoint of one of them
+		for _, other := range partsB.segments {
+			for _, end := range segment {
+				position, _, distance := nearestOnSegment(end, other[0], other[1], mode)
+				consider(end, position, distance)
+			}
+			for _, end := range other {
+				position, _, distance := nearestOnSegment(end, segment[0], segment[1], mode)
+				consider(position, end, distance)
+			}
+		}
+	}
+	return best, NewPoint(clone1(nearestA)), NewPoint(clone1(nearestB))
+}
+
+// geometryParts holds the points, segments and polygon interiors of a geometry
+type geometryParts struct {
+	points   [][]float64
+	segments [][2][]float64
+	polygons [][][][]float64
+}
+
+// decompose breaks a geometry, Feature or FeatureCollection into its parts.
+// Lines with a single position are treated as points.
+func decompose(geometry interface{}) geometryParts {
+	var result geometryParts
+	addLine := func(line [][]float64) {
+		if len(line) == 1 {
+			result.points = append(result.points, line[0])
+		}
+		for inx := 1; inx < len(line); inx++ {
+			result.segments = append(result.segments, [2][]float64{line[inx-1], line[inx]})
+		}
+	}
+	addPolygon := func(polygon [][][]float64) {
+		for _, ring := range polygon {
+			addLine(ring)
+		}
+		if len(polygon) > 0 && len(polygon[0]) > 2 {
+			result.polygons = append(result.polygons, polygon)
+		}
+	}
+	switch typed := geometry.(type) {
+	case *Point:
+		if typed != nil && len(typed.Coordinates) >= 2 {
+			result.points = append(result.points, typed.Coordinates)
+		}
+	case *MultiPoint:
+		for _, position := range typed.Coordinates {
+			if len(position) >= 2 {
+				result.points = append(result.points, position)
+			}
+		}
+	case *LineString:
+		addLine(typed.Coordinates)
+	case *MultiLineString:
+		for _, line := range typed.Coordinates {
+			addLine(line)
+		}
+	case *Polygon:
+		addPolygon(typed.Coordinates)
+	case *MultiPolygon:
+		for _, polygon := range typed.Coordinates {
+			addPolygon(polygon)
+		}
+	case *GeometryCollection:
+		for _, curr := range typed.Geometries {
+			result.add(decompose(curr))
+		}
+	case *Feature:
+		if typed != nil {
+			return decompose(typed.resolvedGeometry())
+		}
+	case *FeatureCollection:
+		if typed != nil {
+			for _, feature := range typed.Features {
+				result.add(decompose(feature))
+			}
+		}
+	}
+	return result
+}
+
+// add appends the parts of another geometry
+func (parts *geometryParts) add(other geometryParts) {
+	parts.points = append(parts.points, other.points...)
+	parts.segments = append(parts.segments, other.segments...)
+	parts.polygons = append(parts.polygons, other.polygons...)
+}
+
+func (parts geometryParts) empty() bool {
+	return len(parts.points) == 0 && len(parts.segments) == 0
+}
+
+// positions returns every position of the points and segments
+func (parts geometryParts) positions() [][]float64 {
+	result := append([][]float64{}, parts.points...)
+	for _, segment := range parts.segments {
+		result = append(result, segment[0], segment[1])
+	}
+	return result
+}
+
+// intersection returns a position shared by the two geometries, or nil if they are disjoint
+func (parts geometryParts) intersection(other geometryParts) []float64 {
+	for _, point := range parts.points {
+		for _, otherPoint := range other.points {
+			if samePosition(point, otherPoint) {
+				return point
+			}
+		}
+		for _, segment := range other.segments {
+			if onSegment(point, segment[0], segment[1]) {
+				return point
+			}
+		}
+	}
+	for _, segment := range parts.segments {
+		for _, otherPoint := range other.points {
+			if onSegment(otherPoint, segment[0], segment[1]) {
+				return otherPoint
+			}
+		}
+		for _, otherSegment := range other.segments {
+			if position := segmentIntersection(segment[0], segment[1], otherSegment[0], otherSegment[1]); position != nil {
+				return position
+			}
+		}
+	}
+	// With no boundaries meeting, one geometry can only lie entirely inside the other
+	if position := parts.insidePolygonOf(other); position != nil {
+		return position
+	}
+	return other.insidePolygonOf(parts)
+}
+
+// insidePolygonOf returns a position of these parts lying inside a polygon of the other, or nil
+func (parts geometryParts) insidePolygonOf(other geometryParts) []float64 {
+	for _, polygon := range other.polygons {
+		for _, position := range parts.positions() {
+			if pointInPolygon(position, polygon) >= 0 {
+				return position
+			}
+		}
+	}
+	return nil
+}
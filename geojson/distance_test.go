@@ -0,0 +1,86 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistancePlanar(t *testing.T) {
+	point := NewPoint([]float64{0, 0})
+	other := NewPoint([]float64{3, 4})
+	distance, nearestA, nearestB := Distance(point, other, Planar)
+	testClose(t, "point-point", 5, distance, 1e-12)
+	if nearestA.String() != point.String() || nearestB.String() != other.String() {
+		t.Errorf("Unexpected nearest points %v %v", nearestA.String(), nearestB.String())
+	}
+
+	ls := NewLineString([][]float64{{-5, 2}, {5, 2}})
+	distance, _, nearestB = Distance(point, ls, Planar)
+	testClose(t, "point-line", 2, distance, 1e-12)
+	if nearestB.String() != `{"type":"Point","coordinates":[0,2]}` {
+		t.Errorf("Unexpected nearest point %v", nearestB.String())
+	}
+
+	polygon := NewPolygon([][][]float64{{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}})
+	distance, nearestA, nearestB = Distance(NewLineString([][]float64{{0, 5}, {7, 12}}), polygon, Planar)
+	testClose(t, "line-polygon", math.Sqrt(13), distance, 1e-12)
+	if nearestA.String() != `{"type":"Point","coordinates":[7,12]}` ||
+		nearestB.String() != `{"type":"Point","coordinates":[10,10]}` {
+		t.Errorf("Unexpected nearest points %v %v", nearestA.String(), nearestB.String())
+	}
+
+	// Crossing lines and a point inside a polygon are zero apart
+	distance, nearestA, _ = Distance(ls, NewLineString([][]float64{{0, 0}, {0, 10}}), Planar)
+	testClose(t, "crossing lines", 0, distance, 0)
+	if nearestA.String() != `{"type":"Point","coordinates":[0,2]}` {
+		t.Errorf("Unexpected intersection %v", nearestA.String())
+	}
+	distance, _, _ = Distance(polygon, NewPoint([]float64{15, 5}), Planar)
+	testClose(t, "point in polygon", 0, distance, 0)
+
+	// A point in a hole is not inside the polygon
+	holed := NewPolygon([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}}})
+	distance, _, _ = Distance(NewPoint([]float64{5, 5}), holed, Planar)
+	testClose(t, "point in hole", 1, distance, 1e-12)
+
+	feature := NewFeature(NewMultiPoint([][]float64{{100, 100}, {0, 3}}), nil, nil)
+	distance, _, _ = Distance(feature, GeometryCollection{Geometries: []interface{}{point}}.Clone(), Planar)
+	testClose(t, "feature-collection", 3, distance, 1e-12)
+	fc := NewFeatureCollection([]*Feature{NewFeature(ls, nil, nil), NewFeature(polygon, nil, nil)})
+	distance, _, _ = Distance(NewPoint([]float64{0, 5}), fc, Planar)
+	testClose(t, "point-featurecollection", 3, distance, 1e-12)
+
+	if distance, nearestA, _ = Distance(point, NewLineString([][]float64{}), Planar); !math.IsNaN(distance) || nearestA != nil {
+		t.Errorf("Expected NaN for an empty geometry, got %v", distance)
+	}
+}
+
+func TestDistanceGeodesic(t *testing.T) {
+	// One degree of latitude north of a segment of the equator
+	ls := NewLineString([][]float64{{-10, 0}, {10, 0}})
+	distance, nearestA, _ := Distance(ls, NewPoint([]float64{2, 1}), Geodesic)
+	testClose(t, "point-line", math.Pi/180*EarthRadius, distance, 1e-6)
+	testClose(t, "nearest longitude", 2, nearestA.Coordinates[0], 1e-9)
+	testClose(t, "nearest latitude", 0, nearestA.Coordinates[1], 1e-9)
+
+	distance, _, _ = Distance(NewPoint([]float64{0, 0}), NewPoint([]float64{180, 0}), Geodesic)
+	testClose(t, "antipodes", math.Pi*EarthRadius, distance, 1e-6)
+}
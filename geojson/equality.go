@@ -0,0 +1,464 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// Equals returns true if the two geometries are of the same type
+// and have exactly the same positions in the same order.
+// Bounding boxes are not considered.
+func Equals(a, b interface{}) bool {
+	return EqualsWithin(a, b, 0)
+}
+
+// EqualsWithin returns true if the two geometries are of the same type
+// and each pair of corresponding ordinates differs by no more than the tolerance
+func EqualsWithin(a, b interface{}, tolerance float64) bool {
+	switch typedA := a.(type) {
+	case *Point:
+		if typedB, ok := b.(*Point); ok && typedA != nil && typedB != nil {
+			return equals1(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *LineString:
+		if typedB, ok := b.(*LineString); ok && typedA != nil && typedB != nil {
+			return equals2(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *Polygon:
+		if typedB, ok := b.(*Polygon); ok && typedA != nil && typedB != nil {
+			return equals3(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *MultiPoint:
+		if typedB, ok := b.(*MultiPoint); ok && typedA != nil && typedB != nil {
+			return equals2(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *MultiLineString:
+		if typedB, ok := b.(*MultiLineString); ok && typedA != nil && typedB != nil {
+			return equals3(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *MultiPolygon:
+		if typedB, ok := b.(*MultiPolygon); ok && typedA != nil && typedB != nil {
+			if len(typedA.Coordinates) != len(typedB.Coordinates) {
+				return false
+			}
+			for inx := range typedA.Coordinates {
+				if !equals3(typedA.Coordinates[inx], typedB.Coordinates[inx], tolerance) {
+					return false
+				}
+			}
+			return true
+		}
+	case *GeometryCollection:
+		if typedB, ok := b.(*GeometryCollection); ok && typedA != nil && typedB != nil {
+			if len(typedA.Geometries) != len(typedB.Geometries) {
+				return false
+			}
+			for inx := range typedA.Geometries {
+				if !EqualsWithin(typedA.Geometries[inx], typedB.Geometries[inx], tolerance) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// EqualsTopologically returns true if the two geometries describe the same shape
+// within the tolerance, ignoring where rings start, the direction of rings and
+// lines, and the order of holes and of the parts of multi-part geometries.
+// Rings, lines and parts are matched to one another within the tolerance,
+// so positions that differ by less than it never change how they are paired.
+func EqualsTopologically(a, b interface{}, tolerance float64) bool {
+	switch typedA := a.(type) {
+	case *Point:
+		if typedB, ok := b.(*Point); ok && typedA != nil && typedB != nil {
+			return equals1(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *LineString:
+		if typedB, ok := b.(*LineString); ok && typedA != nil && typedB != nil {
+			return linesMatch(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *Polygon:
+		if typedB, ok := b.(*Polygon); ok && typedA != nil && typedB != nil {
+			return polygonsMatch(typedA.Coordinates, typedB.Coordinates, tolerance)
+		}
+	case *MultiPoint:
+		if typedB, ok := b.(*MultiPoint); ok && typedA != nil && typedB != nil {
+			return len(typedA.Coordinates) == len(typedB.Coordinates) &&
+				partsMatch(len(typedA.Coordinates), func(i, j int) bool {
+					return equals1(typedA.Coordinates[i], typedB.Coordinates[j], tolerance)
+				})
+		}
+	case *MultiLineString:
+		if typedB, ok := b.(*MultiLineString); ok && typedA != nil && typedB != nil {
+			return len(typedA.Coordinates) == len(typedB.Coordinates) &&
+				partsMatch(len(typedA.Coordinates), func(i, j int) bool {
+					return linesMatch(typedA.Coordinates[i], typedB.Coordinates[j], tolerance)
+				})
+		}
+	case *MultiPolygon:
+		if typedB, ok := b.(*MultiPolygon); ok && typedA != nil && typedB != nil {
+			return len(typedA.Coordinates) == len(typedB.Coordinates) &&
+				partsMatch(len(typedA.Coordinates), func(i, j int) bool {
+					return polygonsMatch(typedA.Coordinates[i], typedB.Coordinates[j], tolerance)
+				})
+		}
+	case *GeometryCollection:
+		if typedB, ok := b.(*GeometryCollection); ok && typedA != nil && typedB != nil {
+			return len(typedA.Geometries) == len(typedB.Geometries) &&
+				partsMatch(len(typedA.Geometries), func(i, j int) bool {
+					return EqualsTopologically(typedA.Geometries[i], typedB.Geometries[j], tolerance)
+				})
+		}
+	}
+	return false
+}
+
+// linesMatch returns true if the lines are equal within the tolerance
+// in either direction
+func linesMatch(a, b [][]float64, tolerance float64) bool {
+	return equals2(a, b, tolerance) || equals2(a, reverseSequence(clone2(b)), tolerance)
+}
+
+// polygonsMatch returns true if the exterior rings match and
+// the holes can be paired so that each pair matches
+func polygonsMatch(a, b [][][]float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	if !ringsMatch(a[0], b[0], tolerance) {
+		return false
+	}
+	holesA, holesB := a[1:], b[1:]
+	return partsMatch(len(holesA), func(i, j int) bool {
+		return ringsMatch(holesA[i], holesB[j], tolerance)
+	})
+}
+
+// ringsMatch returns true if the closed rings are equal within the tolerance
+// when one is rotated to start elsewhere or reversed.
+// Rings that are not closed are compared position by position.
+func ringsMatch(a, b [][]float64, tolerance float64) bool {
+	closed := func(ring [][]float64) bool {
+		return len(ring) >= 4 && samePosition(ring[0], ring[len(ring)-1])
+	}
+	if !closed(a) || !closed(b) {
+		return equals2(a, b, tolerance)
+	}
+	openA, openB := a[:len(a)-1], b[:len(b)-1]
+	count := len(openA)
+	if len(openB) != count {
+		return false
+	}
+	for start := 0; start < count; start++ {
+		for _, step := range []int{1, count - 1} {
+			matched := true
+			for inx := 0; inx < count && matched; inx++ {
+				matched = equals1(openA[inx], openB[(start+inx*step)%count], tolerance)
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// partsMatch returns true if each of count parts can be paired with a
+// different one of count others such that every pair matches,
+// using augmenting paths so that an early pairing never blocks a later one
+func partsMatch(count int, match func(i, j int) bool) bool {
+	matches := make([][]bool, count)
+	for i := range matches {
+		matches[i] = make([]bool, count)
+		for j := range matches[i] {
+			matches[i][j] = match(i, j)
+		}
+	}
+	pairedWith := make([]int, count)
+	for j := range pairedWith {
+		pairedWith[j] = -1
+	}
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for j := 0; j < count; j++ {
+			if !matches[i][j] || visited[j] {
+				continue
+			}
+			visited[j] = true
+			if pairedWith[j] < 0 || augment(pairedWith[j], visited) {
+				pairedWith[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i < count; i++ {
+		if !augment(i, make([]bool, count)) {
+			return false
+		}
+	}
+	return true
+}
+
+func equals1(a, b []float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for inx := range a {
+		if !(math.Abs(a[inx]-b[inx]) <= tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func equals2(a, b [][]float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for inx := range a {
+		if !equals1(a[inx], b[inx], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func equals3(a, b [][][]float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for inx := range a {
+		if !equals2(a[inx], b[inx], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize returns a copy of the geometry in a canonical form, so that
+// geometries describing the same shape produce the same output:
+// lines run from their lexicographically smaller end,
+// rings start at their lexicographically smallest position and follow the
+// RFC 7946 right-hand rule (exterior rings counterclockwise, holes clockwise),
+// and holes and the parts of multi-part geometries are sorted
+func Normalize(geometry interface{}) interface{} {
+	switch typed := geometry.(type) {
+	case *Point:
+		if typed == nil {
+			return typed
+		}
+		return NewPoint(clone1(typed.Coordinates))
+	case *LineString:
+		if typed == nil {
+			return typed
+		}
+		return NewLineString(normalizeLine(typed.Coordinates))
+	case *Polygon:
+		if typed == nil {
+			return typed
+		}
+		return NewPolygon(normalizePolygon(typed.Coordinates))
+	case *MultiPoint:
+		if typed == nil {
+			return typed
+		}
+		coordinates := clone2(typed.Coordinates)
+		sort.Slice(coordinates, func(i, j int) bool {
+			return comparePositions(coordinates[i], coordinates[j]) < 0
+		})
+		return NewMultiPoint(coordinates)
+	case *MultiLineString:
+		if typed == nil {
+			return typed
+		}
+		var coordinates [][][]float64
+		for _, line := range typed.Coordinates {
+			coordinates = append(coordinates, normalizeLine(line))
+		}
+		sort.Slice(coordinates, func(i, j int) bool {
+			return compareSequences(coordinates[i], coordinates[j]) < 0
+		})
+		return NewMultiLineString(coordinates)
+	case *MultiPolygon:
+		if typed == nil {
+			return typed
+		}
+		var coordinates [][][][]float64
+		for _, polygon := range typed.Coordinates {
+			coordinates = append(coordinates, normalizePolygon(polygon))
+		}
+		sort.Slice(coordinates, func(i, j int) bool {
+			return comparePolygons(coordinates[i], coordinates[j]) < 0
+		})
+		return NewMultiPolygon(coordinates)
+	case *GeometryCollection:
+		if typed == nil {
+			return typed
+		}
+		geometries := make([]interface{}, len(typed.Geometries))
+		for inx, curr := range typed.Geometries {
+			geometries[inx] = Normalize(curr)
+		}
+		sort.Slice(geometries, func(i, j int) bool {
+			return compareGeometries(geometries[i], geometries[j]) < 0
+		})
+		return NewGeometryCollection(geometries)
+	}
+	return geometry
+}
+
+func normalizeLine(line [][]float64) [][]float64 {
+	result := clone2(line)
+	reversed := reverseSequence(clone2(line))
+	if compareSequences(reversed, result) < 0 {
+		return reversed
+	}
+	return result
+}
+
+func normalizePolygon(polygon [][][]float64) [][][]float64 {
+	if polygon == nil {
+		return nil
+	}
+	result := make([][][]float64, len(polygon))
+	for inx, ring := range polygon {
+		result[inx] = normalizeRing(ring, inx == 0)
+	}
+	if len(result) > 2 {
+		holes := result[1:]
+		sort.Slice(holes, func(i, j int) bool {
+			return compareSequences(holes[i], holes[j]) < 0
+		})
+	}
+	return result
+}
+
+// normalizeRing orients a closed ring and rotates it to start
+// at its smallest position; rings that are not closed are only copied
+func normalizeRing(ring [][]float64, exterior bool) [][]float64 {
+	result := clone2(ring)
+	if len(result) < 4 || !samePosition(result[0], result[len(result)-1]) {
+		return result
+	}
+	open := result[:len(result)-1]
+	if area := ringArea(open); (exterior && area < 0) || (!exterior && area > 0) {
+		reverseSequence(open)
+	}
+	smallest := 0
+	for inx := range open {
+		if comparePositions(open[inx], open[smallest]) < 0 {
+			smallest = inx
+		}
+	}
+	rotated := append(append([][]float64{}, open[smallest:]...), open[:smallest]...)
+	return append(rotated, clone1(rotated[0]))
+}
+
+// ringArea returns the signed planar area of a ring, which is
+// positive when the ring is counterclockwise.
+// The ring may or may not repeat its first position at the end.
+func ringArea(ring [][]float64) float64 {
+	var result float64
+	for inx := range ring {
+		curr := ring[inx]
+		next := ring[(inx+1)%len(ring)]
+		result += curr[0]*next[1] - next[0]*curr[1]
+	}
+	return result / 2
+}
+
+func reverseSequence(sequence [][]float64) [][]float64 {
+	for i, j := 0, len(sequence)-1; i < j; i, j = i+1, j-1 {
+		sequence[i], sequence[j] = sequence[j], sequence[i]
+	}
+	return sequence
+}
+
+func comparePositions(a, b []float64) int {
+	for inx := 0; inx < len(a) && inx < len(b); inx++ {
+		if a[inx] < b[inx] {
+			return -1
+		}
+		if a[inx] > b[inx] {
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+func compareSequences(a, b [][]float64) int {
+	for inx := 0; inx < len(a) && inx < len(b); inx++ {
+		if result := comparePositions(a[inx], b[inx]); result != 0 {
+			return result
+		}
+	}
+	return len(a) - len(b)
+}
+
+func comparePolygons(a, b [][][]float64) int {
+	for inx := 0; inx < len(a) && inx < len(b); inx++ {
+		if result := compareSequences(a[inx], b[inx]); result != 0 {
+			return result
+		}
+	}
+	return len(a) - len(b)
+}
+
+var geometryTypeOrder = map[string]int{
+	POINT:              1,
+	LINESTRING:         2,
+	POLYGON:            3,
+	MULTIPOINT:         4,
+	MULTILINESTRING:    5,
+	MULTIPOLYGON:       6,
+	GEOMETRYCOLLECTION: 7}
+
+func compareGeometries(a, b interface{}) int {
+	if result := geometryTypeOrder[geometryType(a)] - geometryTypeOrder[geometryType(b)]; result != 0 {
+		return result
+	}
+	return compareSequences(interfaceTo2DArray(a), interfaceTo2DArray(b))
+}
+
+// geometryType returns the GeoJSON type name of a geometry object
+func geometryType(geometry interface{}) string {
+	switch geometry.(type) {
+	case *Point:
+		return POINT
+	case *LineString:
+		return LINESTRING
+	case *Polygon:
+		return POLYGON
+	case *MultiPoint:
+		return MULTIPOINT
+	case *MultiLineString:
+		return MULTILINESTRING
+	case *MultiPolygon:
+		return MULTIPOLYGON
+	case *GeometryCollection:
+		return GEOMETRYCOLLECTION
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+func TestEquals(t *testing.T) {
+	for _, fileName := range inputFilesForWKT {
+		gj, err := ParseFile(fileName)
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+		if !Equals(gj, cloneGeometry(gj)) {
+			t.Errorf("Expected %v to equal its clone", fileName)
+		}
+	}
+	a := NewLineString([][]float64{{0, 0}, {1, 1}})
+	b := NewLineString([][]float64{{0, 0}, {1, 1.0000001}})
+	if Equals(a, b) {
+		t.Error("Did not expect exact equality")
+	}
+	if !EqualsWithin(a, b, 1e-6) {
+		t.Error("Expected equality within tolerance")
+	}
+	if EqualsWithin(a, NewMultiPoint(a.Coordinates), 1e-6) {
+		t.Error("Did not expect geometries of different types to be equal")
+	}
+	if Equals(a, NewLineString([][]float64{{0, 0}, {1, 1}, {2, 2}})) {
+		t.Error("Did not expect lines of different lengths to be equal")
+	}
+	var (
+		nilLine    *LineString
+		nilPolygon *MultiPolygon
+	)
+	if Equals(a, nilLine) || Equals(nilLine, a) || Equals(nilPolygon, nilPolygon) {
+		t.Error("Did not expect nil geometries to be equal")
+	}
+	if Normalize(nilLine) != nilLine || Normalize(nilPolygon) != nilPolygon {
+		t.Error("Expected nil geometries to normalize to themselves")
+	}
+}
+
+func TestEqualsTopologically(t *testing.T) {
+	a := NewPolygon([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+		{{6, 6}, {6, 8}, {8, 8}, {8, 6}, {6, 6}}})
+	b := NewPolygon([][][]float64{
+		{{10, 10}, {10, 0}, {0, 0}, {0, 10}, {10, 10}},
+		{{8, 8}, {6, 8}, {6, 6}, {8, 6}, {8, 8}},
+		{{4, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 4}}})
+	if Equals(a, b) {
+		t.Error("Did not expect exact equality")
+	}
+	if !EqualsTopologically(a, b, 0) {
+		t.Errorf("Expected topological equality:\n%v\n%v", Normalize(a), Normalize(b))
+	}
+	expected := `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,4],[4,4],[4,2],[2,2]],[[6,6],[6,8],[8,8],[8,6],[6,6]]]}`
+	if normalized := Normalize(b).(*Polygon); normalized.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, normalized.String())
+	}
+
+	mls1 := NewMultiLineString([][][]float64{{{5, 5}, {0, 0}}, {{1, 1}, {2, 2}}})
+	mls2 := NewMultiLineString([][][]float64{{{2, 2}, {1, 1}}, {{0, 0}, {5, 5}}})
+	if !EqualsTopologically(mls1, mls2, 0) {
+		t.Error("Expected multilinestrings to be topologically equal")
+	}
+	mp1 := NewMultiPoint([][]float64{{1, 2}, {0, 0}})
+	mp2 := NewMultiPoint([][]float64{{0, 0}, {1, 2}})
+	if !EqualsTopologically(mp1, mp2, 0) {
+		t.Error("Expected multipoints to be topologically equal")
+	}
+	gc1 := NewGeometryCollection([]interface{}{mp1, a})
+	gc2 := NewGeometryCollection([]interface{}{b, mp2})
+	if !EqualsTopologically(gc1, gc2, 0) {
+		t.Error("Expected geometry collections to be topologically equal")
+	}
+	if EqualsTopologically(a, NewPolygon(a.Coordinates[:1]), 0) {
+		t.Error("Did not expect polygons with different holes to be equal")
+	}
+	if Equals(a.Coordinates[0], a.Coordinates[0]) {
+		t.Error("Did not expect raw coordinates to be comparable")
+	}
+
+	// Noise within the tolerance changes the smallest position of a ring and
+	// the order of parts, which must not change how they are paired
+	noisy := NewPolygon([][][]float64{{{0.0001, 0}, {10, 0}, {10, 10}, {0, 10}, {0.0001, 0}}})
+	clean := NewPolygon([][][]float64{{{10, 10}, {0.0001, 10}, {0, 0}, {10, 0}, {10, 10}}})
+	if EqualsWithin(Normalize(noisy), Normalize(clean), 0.001) {
+		t.Error("Expected normalization to start the rings at different positions")
+	}
+	if !EqualsTopologically(noisy, clean, 0.001) {
+		t.Error("Expected rings with noise within the tolerance to be equal")
+	}
+	if EqualsTopologically(noisy, clean, 0.00001) {
+		t.Error("Did not expect rings with noise beyond the tolerance to be equal")
+	}
+	points1 := NewMultiPoint([][]float64{{1, 5}, {1.0001, 0}})
+	points2 := NewMultiPoint([][]float64{{1.0001, 5}, {1, 0}})
+	if !EqualsTopologically(points1, points2, 0.001) {
+		t.Error("Expected parts with noise within the tolerance to be paired")
+	}
+	lines1 := NewMultiLineString([][][]float64{{{0, 0}, {1, 1}}, {{0.0001, 0}, {1, 2}}})
+	lines2 := NewMultiLineString([][][]float64{{{1, 2}, {0, 0}}, {{0.0001, 0}, {1, 1}}})
+	if !EqualsTopologically(lines1, lines2, 0.001) {
+		t.Error("Expected lines to be paired without being blocked by an earlier pairing")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"reflect"
 	"strconv"
 )
 
@@ -111,12 +112,125 @@ func NewFeature(geometry interface{}, id interface{}, properties map[string]inte
 	return &Feature{Type: FEATURE, Geometry: geometry, Properties: properties, ID: id}
 }
 
+// Clone returns a deep copy of the Feature,
+// including its geometry, properties, and bounding box,
+// or nil if the Feature is nil
+func (feature *Feature) Clone() *Feature {
+	if feature == nil {
+		return nil
+	}
+	return &Feature{
+		Type:       feature.Type,
+		Geometry:   cloneGeometry(feature.Geometry),
+		Properties: cloneProperties(feature.Properties),
+		ID:         cloneValue(feature.ID),
+		Bbox:       feature.Bbox.clone()}
+}
+
+func cloneProperties(input map[string]interface{}) map[string]interface{} {
+	if input == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		result[key] = cloneValue(value)
+	}
+	return result
+}
+
+// cloneValue returns a deep copy of a property value,
+// descending into any maps, slices, arrays, and pointers it contains.
+// Structs are copied by value, so values their fields point to are shared.
+func cloneValue(input interface{}) interface{} {
+	switch it := input.(type) {
+	case nil, string, bool, float64, float32, int, int64, int32, uint, uint64, uint32, json.Number:
+		return it
+	case map[string]interface{}:
+		return cloneProperties(it)
+	case []interface{}:
+		if it == nil {
+			return it
+		}
+		result := make([]interface{}, len(it))
+		for inx, value := range it {
+			result[inx] = cloneValue(value)
+		}
+		return result
+	}
+	return cloneReflect(reflect.ValueOf(input)).Interface()
+}
+
+func cloneReflect(input reflect.Value) reflect.Value {
+	switch input.Kind() {
+	case reflect.Interface:
+		if input.IsNil() {
+			return input
+		}
+		result := reflect.New(input.Type()).Elem()
+		result.Set(cloneReflect(input.Elem()))
+		return result
+	case reflect.Map:
+		if input.IsNil() {
+			return input
+		}
+		result := reflect.MakeMapWithSize(input.Type(), input.Len())
+		for _, key := range input.MapKeys() {
+			result.SetMapIndex(key, cloneReflect(input.MapIndex(key)))
+		}
+		return result
+	case reflect.Slice:
+		if input.IsNil() {
+			return input
+		}
+		result := reflect.MakeSlice(input.Type(), input.Len(), input.Len())
+		for inx := 0; inx < input.Len(); inx++ {
+			result.Index(inx).Set(cloneReflect(input.Index(inx)))
+		}
+		return result
+	case reflect.Ptr:
+		if input.IsNil() {
+			return input
+		}
+		result := reflect.New(input.Type().Elem())
+		result.Elem().Set(cloneReflect(input.Elem()))
+		return result
+	case reflect.Array:
+		result := reflect.New(input.Type()).Elem()
+		for inx := 0; inx < input.Len(); inx++ {
+			result.Index(inx).Set(cloneReflect(input.Index(inx)))
+		}
+		return result
+	}
+	return input
+}
+
 // ResolveGeometry reconstructs a Feature's geometries
 // since unmarshaled objects come back as maps of interfaces, not real geometries
 func (feature *Feature) ResolveGeometry() {
 	feature.Geometry = newGeometry(feature.Geometry)
 }
 
+// resolvedBbox returns the Feature's bounding box as ForceBbox would,
+// leaving the Feature unchanged
+func (feature *Feature) resolvedBbox() BoundingBox {
+	if len(feature.Bbox) > 0 {
+		return feature.Bbox
+	}
+	if bboxIfc, ok := feature.resolvedGeometry().(BoundingBoxIfc); ok {
+		return bboxIfc.ForceBbox()
+	}
+	return BoundingBox{}
+}
+
+// resolvedGeometry returns the Feature's geometry as ResolveGeometry would
+// reconstruct it, leaving the Feature unchanged
+func (feature *Feature) resolvedGeometry() interface{} {
+	if feature == nil {
+		return nil
+	}
+	return newGeometry(feature.Geometry)
+}
+
 func stringify(input interface{}) string {
 	var result string
 	switch itype := input.(type) {
@@ -64,6 +64,24 @@ func NewFeatureCollection(features []*Feature) *FeatureCollection {
 	return &FeatureCollection{Type: FEATURECOLLECTION, Features: features}
 }
 
+// Clone returns a deep copy of the FeatureCollection and all of its Features,
+// or nil if the FeatureCollection is nil
+func (fc *FeatureCollection) Clone() *FeatureCollection {
+	if fc == nil {
+		return nil
+	}
+	var features []*Feature
+	if fc.Features != nil {
+		features = make([]*Feature, len(fc.Features))
+		for inx, feature := range fc.Features {
+			if feature != nil {
+				features[inx] = feature.Clone()
+			}
+		}
+	}
+	return &FeatureCollection{Type: fc.Type, Features: features, Bbox: fc.Bbox.clone()}
+}
+
 // String returns the string representation
 func (fc *FeatureCollection) String() string {
 	var result string
@@ -18,6 +18,7 @@ package geojson
 
 import (
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -158,3 +159,78 @@ func TestFeatureMarshalEmptyProperties(t *testing.T) {
 		t.Errorf("Nil properties did not output an empty object: %v", f.String())
 	}
 }
+
+// TestUnresolvedGeometryInput checks that functions reading Features whose
+// geometries are still unmarshaled maps resolve them without modifying the input
+func TestUnresolvedGeometryInput(t *testing.T) {
+	testCases := []struct {
+		name string
+		// count returns how many results the Features produced
+		count func(fc *FeatureCollection) (int, error)
+	}{
+		{"NewRTree", func(fc *FeatureCollection) (int, error) {
+			return len(NewRTree(fc).SearchPoint(NewPoint([]float64{1, 2}))), nil
+		}},
+		{"RTree.Insert", func(fc *FeatureCollection) (int, error) {
+			tree := NewRTree(nil)
+			tree.Insert(fc.Features[0])
+			return len(tree.SearchPoint(NewPoint([]float64{1, 2}))), nil
+		}},
+		{"SpatialJoin", func(fc *FeatureCollection) (int, error) {
+			return len(SpatialJoin(fc, fc, JoinOptions{}).Features), nil
+		}},
+		{"EncodeMVT", func(fc *FeatureCollection) (int, error) {
+			tile := TileForPoint(1, 2, 10)
+			data, err := EncodeMVT(fc, "features", tile, 4096)
+			if err != nil {
+				return 0, err
+			}
+			layers, err := DecodeMVT(data, tile)
+			if err != nil || layers["features"] == nil {
+				return 0, err
+			}
+			return len(layers["features"].Features), nil
+		}},
+		{"TileIndex", func(fc *FeatureCollection) (int, error) {
+			result, err := NewTileIndex(fc, TileIndexOptions{}).GetTile(0, 0, 0)
+			if err != nil {
+				return 0, err
+			}
+			return len(result.Features), nil
+		}},
+		{"ToTopoJSON", func(fc *FeatureCollection) (int, error) {
+			count := 0
+			for _, geometry := range ToTopoJSON(fc, "points", 0).Objects["points"].Geometries {
+				if geometry.Type == POINT {
+					count++
+				}
+			}
+			return count, nil
+		}},
+		{"ToKML", func(fc *FeatureCollection) (int, error) {
+			data, err := ToKML(fc, KMLOptions{})
+			return strings.Count(string(data), "<coordinates>1,2</coordinates>"), err
+		}},
+		{"ToGPX", func(fc *FeatureCollection) (int, error) {
+			data, err := ToGPX(fc)
+			if err != nil {
+				return 0, err
+			}
+			result, err := FromGPX(data)
+			if err != nil {
+				return 0, err
+			}
+			return len(result.Features), nil
+		}},
+	}
+	for _, testCase := range testCases {
+		unresolved := NewFeature(map[string]interface{}{"type": "Point", "coordinates": []interface{}{1.0, 2.0}}, nil, nil)
+		count, err := testCase.count(NewFeatureCollection([]*Feature{unresolved}))
+		if err != nil || count != 1 {
+			t.Errorf("%v: expected one result from the unmarshaled point, got %v, %v", testCase.name, count, err)
+		}
+		if _, ok := unresolved.Geometry.(map[string]interface{}); !ok {
+			t.Errorf("%v: expected the input geometry to be unchanged, got %T", testCase.name, unresolved.Geometry)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "math"
+
+// The functions in this file work on the WGS84 ellipsoid with longitude/latitude
+// points. Distances are in metres and bearings in degrees clockwise from north,
+// between 0 and 360. Ordinates beyond the first two, such as elevation,
+// are carried over from the starting point.
+// Geodesics are computed with Vincenty's formulae, which do not converge
+// for nearly antipodal points; those results are NaN.
+
+// GeodesicDistance returns the length of the shortest path between two points
+func GeodesicDistance(from, to *Point) float64 {
+	distance, _, _ := vincentyInverse(from, to)
+	return distance
+}
+
+// InitialBearing returns the bearing at the start of the shortest path between two points
+func InitialBearing(from, to *Point) float64 {
+	_, bearing, _ := vincentyInverse(from, to)
+	return bearing
+}
+
+// FinalBearing returns the bearing at the end of the shortest path between two points
+func FinalBearing(from, to *Point) float64 {
+	_, _, bearing := vincentyInverse(from, to)
+	return bearing
+}
+
+// Destination returns the point reached by travelling the distance
+// along the geodesic starting at the bearing provided
+func Destination(from *Point, distance, bearing float64) *Point {
+	if !validLonLat(from) {
+		return nil
+	}
+	longitude, latitude := vincentyDirect(from.Coordinates[0], from.Coordinates[1], distance, bearing)
+	return destinationPoint(from, longitude, latitude)
+}
+
+// Midpoint returns the point halfway along the shortest path between two points
+func Midpoint(from, to *Point) *Point {
+	distance, bearing, _ := vincentyInverse(from, to)
+	if math.IsNaN(distance) {
+		return nil
+	}
+	return midpoint(from, to, Destination(from, distance/2, bearing))
+}
+
+// RhumbDistance returns the length of the line of constant bearing between two points
+func RhumbDistance(from, to *Point) float64 {
+	distance, _ := rhumbInverse(from, to)
+	return distance
+}
+
+// RhumbBearing returns the constant bearing of the rhumb line between two points
+func RhumbBearing(from, to *Point) float64 {
+	_, bearing := rhumbInverse(from, to)
+	return bearing
+}
+
+// RhumbDestination returns the point reached by travelling the distance
+// at a constant bearing. Paths that would pass a pole stop at it, keeping
+// the starting longitude, since a rhumb line circles a pole endlessly
+// and every longitude meets there.
+func RhumbDestination(from *Point, distance, bearing float64) *Point {
+	if !validLonLat(from) {
+		return nil
+	}
+	theta := toRadians(bearing)
+	phi1 := toRadians(from.Coordinates[1])
+	phi2 := inverseMeridianArc(meridianArc(phi1) + distance*math.Cos(theta))
+	phi2 = math.Max(-math.Pi/2, math.Min(math.Pi/2, phi2))
+
+	var deltaLambda float64
+	switch {
+	case math.Abs(phi1) >= math.Pi/2 || math.Abs(phi2) >= math.Pi/2:
+		// The longitude at a pole is arbitrary
+	case math.Abs(phi2-phi1) > 1e-12:
+		deltaLambda = (isometricLatitude(phi2) - isometricLatitude(phi1)) * math.Tan(theta)
+	default:
+		// Travelling along a parallel
+		deltaLambda = distance * math.Sin(theta) / parallelRadius(phi1)
+	}
+	longitude := normalizeLongitude(from.Coordinates[0] + toDegrees(deltaLambda))
+	return destinationPoint(from, longitude, toDegrees(phi2))
+}
+
+// RhumbMidpoint returns the point halfway along the rhumb line between two points
+func RhumbMidpoint(from, to *Point) *Point {
+	distance, bearing := rhumbInverse(from, to)
+	if math.IsNaN(distance) {
+		return nil
+	}
+	return midpoint(from, to, RhumbDestination(from, distance/2, bearing))
+}
+
+func validLonLat(point *Point) bool {
+	return point != nil && len(point.Coordinates) >= 2
+}
+
+func destinationPoint(from *Point, longitude, latitude float64) *Point {
+	result := clone1(from.Coordinates)
+	result[0], result[1] = longitude, latitude
+	return NewPoint(result)
+}
+
+// midpoint averages the extra ordinates of two points into a computed midpoint
+func midpoint(from, to, middle *Point) *Point {
+	if len(from.Coordinates) == len(to.Coordinates) {
+		result := interpolatePosition(from.Coordinates, to.Coordinates, 0.5)
+		result[0], result[1] = middle.Coordinates[0], middle.Coordinates[1]
+		return NewPoint(result)
+	}
+	return middle
+}
+
+func normalizeBearing(radians float64) float64 {
+	return math.Mod(toDegrees(radians)+360, 360)
+}
+
+const (
+	wgs84SemiMinorAxis   = wgs84SemiMajorAxis * (1 - wgs84Flattening)
+	wgs84Eccentricity2   = wgs84Flattening * (2 - wgs84Flattening)
+	vincentyMaxIteration = 200
+)
+
+// vincentyTerms returns Vincenty's A and B coefficients for the squared cosine of the azimuth at the equator
+func vincentyTerms(cos2Alpha float64) (float64, float64) {
+	a2, b2 := wgs84SemiMajorAxis*wgs84SemiMajorAxis, wgs84SemiMinorAxis*wgs84SemiMinorAxis
+	u2 := cos2Alpha * (a2 - b2) / b2
+	a := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+	b := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+	return a, b
+}
+
+func vincentyDeltaSigma(b, sinSigma, cosSigma, cos2SigmaM float64) float64 {
+	return b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+}
+
+// vincentyInverse returns the geodesic distance between two points
+// and the initial and final bearings
+func vincentyInverse(from, to *Point) (float64, float64, float64) {
+	if !validLonLat(from) || !validLonLat(to) {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	f := wgs84Flattening
+	l := toRadians(to.Coordinates[0] - from.Coordinates[0])
+	u1 := math.Atan((1 - f) * math.Tan(toRadians(from.Coordinates[1])))
+	u2 := math.Atan((1 - f) * math.Tan(toRadians(to.Coordinates[1])))
+	sinU1, cosU1 := math.Sin(u1), math.Cos(u1)
+	sinU2, cosU2 := math.Sin(u2), math.Cos(u2)
+
+	var (
+		lambda                                      = l
+		sinLambda, cosLambda                        float64
+		sinSigma, cosSigma, sigma, cos2Alpha, cos2M float64
+		converged                                   bool
+	)
+	for iteration := 0; iteration < vincentyMaxIteration; iteration++ {
+		sinLambda, cosLambda = math.Sin(lambda), math.Cos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			// The points coincide
+			return 0, 0, 0
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2M = 0
+		if cos2Alpha != 0 {
+			cos2M = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		previous := lambda
+		lambda = l + (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2M+c*cosSigma*(-1+2*cos2M*cos2M)))
+		if math.Abs(lambda-previous) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	a, b := vincentyTerms(cos2Alpha)
+	distance := wgs84SemiMinorAxis * a * (sigma - vincentyDeltaSigma(b, sinSigma, cosSigma, cos2M))
+	initial := math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+	final := math.Atan2(cosU1*sinLambda, -sinU1*cosU2+cosU1*sinU2*cosLambda)
+	return distance, normalizeBearing(initial), normalizeBearing(final)
+}
+
+// vincentyDirect returns the longitude and latitude reached from a starting position
+func vincentyDirect(longitude, latitude, distance, bearing float64) (float64, float64) {
+	f := wgs84Flattening
+	alpha1 := toRadians(bearing)
+	sinAlpha1, cosAlpha1 := math.Sin(alpha1), math.Cos(alpha1)
+	tanU1 := (1 - f) * math.Tan(toRadians(latitude))
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	sigma1 := math.Atan2(tanU1, cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cos2Alpha := 1 - sinAlpha*sinAlpha
+	a, b := vincentyTerms(cos2Alpha)
+
+	var (
+		sigma                     = distance / (wgs84SemiMinorAxis * a)
+		sinSigma, cosSigma, cos2M float64
+	)
+	for iteration := 0; iteration < vincentyMaxIteration; iteration++ {
+		cos2M = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sin(sigma), math.Cos(sigma)
+		previous := sigma
+		sigma = distance/(wgs84SemiMinorAxis*a) + vincentyDeltaSigma(b, sinSigma, cosSigma, cos2M)
+		if math.Abs(sigma-previous) < 1e-12 {
+			break
+		}
+	}
+	sinSigma, cosSigma = math.Sin(sigma), math.Cos(sigma)
+	cos2M = math.Cos(2*sigma1 + sigma)
+
+	x := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	phi2 := math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-f)*math.Hypot(sinAlpha, x))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+	l := lambda - (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2M+c*cosSigma*(-1+2*cos2M*cos2M)))
+	return normalizeLongitude(longitude + toDegrees(l)), toDegrees(phi2)
+}
+
+// rhumbInverse returns the rhumb line distance and bearing between two points,
+// crossing the antimeridian if that is shorter
+func rhumbInverse(from, to *Point) (float64, float64) {
+	if !validLonLat(from) || !validLonLat(to) {
+		return math.NaN(), math.NaN()
+	}
+	phi1, phi2 := toRadians(from.Coordinates[1]), toRadians(to.Coordinates[1])
+	deltaLambda := toRadians(normalizeLongitude(to.Coordinates[0] - from.Coordinates[0]))
+	deltaPsi := isometricLatitude(phi2) - isometricLatitude(phi1)
+	theta := math.Atan2(deltaLambda, deltaPsi)
+
+	var distance float64
+	if math.Abs(phi2-phi1) > 1e-12 {
+		distance = (meridianArc(phi2) - meridianArc(phi1)) / math.Cos(theta)
+	} else {
+		// Travelling along a parallel
+		distance = math.Abs(deltaLambda) * parallelRadius(phi1)
+	}
+	return distance, normalizeBearing(theta)
+}
+
+// isometricLatitude returns the isometric latitude on the ellipsoid, in radians
+func isometricLatitude(phi float64) float64 {
+	e := math.Sqrt(wgs84Eccentricity2)
+	return math.Atanh(math.Sin(phi)) - e*math.Atanh(e*math.Sin(phi))
+}
+
+// parallelRadius returns the radius of the parallel at a latitude
+func parallelRadius(phi float64) float64 {
+	sinPhi := math.Sin(phi)
+	return wgs84SemiMajorAxis * math.Cos(phi) / math.Sqrt(1-wgs84Eccentricity2*sinPhi*sinPhi)
+}
+
+// meridianArc returns the distance along a meridian from the equator to a latitude
+func meridianArc(phi float64) float64 {
+	e2 := wgs84Eccentricity2
+	e4, e6 := e2*e2, e2*e2*e2
+	return wgs84SemiMajorAxis * ((1-e2/4-3*e4/64-5*e6/256)*phi -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*phi) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*phi) -
+		(35*e6/3072)*math.Sin(6*phi))
+}
+
+// inverseMeridianArc returns the latitude at a distance along a meridian
+// from the equator, by Newton's method
+func inverseMeridianArc(arc float64) float64 {
+	e2 := wgs84Eccentricity2
+	phi := arc / wgs84SemiMajorAxis
+	for iteration := 0; iteration < 20; iteration++ {
+		sinPhi := math.Sin(phi)
+		derivative := wgs84SemiMajorAxis * (1 - e2) / math.Pow(1-e2*sinPhi*sinPhi, 1.5)
+		delta := (meridianArc(phi) - arc) / derivative
+		phi -= delta
+		if math.Abs(delta) < 1e-14 {
+			break
+		}
+	}
+	return phi
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeodesic(t *testing.T) {
+	// Vincenty's example from Flinders Peak to Buninyong
+	flinders := NewPoint([]float64{144.42486788888889, -37.95103341666667})
+	buninyong := NewPoint([]float64{143.92649552777778, -37.65282113888889})
+	testClose(t, "distance", 54972.271, GeodesicDistance(flinders, buninyong), 1e-3)
+	testClose(t, "initial bearing", 306.8681583, InitialBearing(flinders, buninyong), 1e-6)
+	testClose(t, "final bearing", 307.1736306, FinalBearing(flinders, buninyong), 1e-6)
+
+	destination := Destination(flinders, 54972.271, 306.8681583)
+	testClose(t, "destination longitude", buninyong.Coordinates[0], destination.Coordinates[0], 1e-7)
+	testClose(t, "destination latitude", buninyong.Coordinates[1], destination.Coordinates[1], 1e-7)
+
+	middle := Midpoint(flinders, buninyong)
+	testClose(t, "midpoint to start", 54972.271/2, GeodesicDistance(flinders, middle), 1e-3)
+	testClose(t, "midpoint to end", 54972.271/2, GeodesicDistance(middle, buninyong), 1e-3)
+
+	// A quarter of the equator
+	equator := GeodesicDistance(NewPoint([]float64{0, 0}), NewPoint([]float64{90, 0}))
+	testClose(t, "equator", wgs84SemiMajorAxis*math.Pi/2, equator, 1e-5)
+	testClose(t, "coincident", 0, GeodesicDistance(flinders, flinders), 0)
+
+	// Elevation is carried over
+	destination = Destination(NewPoint([]float64{0, 0, 100}), 1000, 0)
+	if len(destination.Coordinates) != 3 || destination.Coordinates[2] != 100 {
+		t.Errorf("Expected elevation to be kept, got %v", destination.String())
+	}
+	if Destination(nil, 1, 1) != nil || !math.IsNaN(GeodesicDistance(nil, flinders)) {
+		t.Error("Expected nil points to be rejected")
+	}
+}
+
+func TestRhumb(t *testing.T) {
+	origin := NewPoint([]float64{0, 0})
+	testClose(t, "equator distance", wgs84SemiMajorAxis*math.Pi/180, RhumbDistance(origin, NewPoint([]float64{1, 0})), 1e-6)
+	testClose(t, "equator bearing", 90, RhumbBearing(origin, NewPoint([]float64{1, 0})), 1e-9)
+	// The length of the meridian quadrant
+	testClose(t, "meridian distance", 10001965.729, RhumbDistance(origin, NewPoint([]float64{0, 90})), 1e-3)
+
+	// Rhumb lines cross the antimeridian when that is shorter
+	west := NewPoint([]float64{179, 10})
+	east := NewPoint([]float64{-179, 10})
+	testClose(t, "antimeridian bearing", 90, RhumbBearing(west, east), 1e-9)
+	middle := RhumbMidpoint(west, east)
+	testClose(t, "antimeridian midpoint", 180, math.Abs(middle.Coordinates[0]), 1e-9)
+	testClose(t, "antimeridian midpoint latitude", 10, middle.Coordinates[1], 1e-9)
+
+	from := NewPoint([]float64{-73.98, 40.75})
+	to := NewPoint([]float64{-0.12, 51.5})
+	distance, bearing := RhumbDistance(from, to), RhumbBearing(from, to)
+	if distance <= GeodesicDistance(from, to) {
+		t.Errorf("Expected the rhumb line %v to be longer than the geodesic", distance)
+	}
+	destination := RhumbDestination(from, distance, bearing)
+	testClose(t, "destination longitude", to.Coordinates[0], destination.Coordinates[0], 1e-8)
+	testClose(t, "destination latitude", to.Coordinates[1], destination.Coordinates[1], 1e-8)
+
+	// Paths past a pole stop at it with a finite longitude
+	for _, bearing := range []float64{0, 45, 180} {
+		latitude := 90.0
+		if bearing == 180 {
+			latitude = -90
+		}
+		destination = RhumbDestination(from, 3e7, bearing)
+		testClose(t, "pole latitude", latitude, destination.Coordinates[1], 1e-9)
+		testClose(t, "pole longitude", from.Coordinates[0], destination.Coordinates[0], 1e-9)
+	}
+	destination = RhumbDestination(NewPoint([]float64{10, 90}), 1e6, 135)
+	if math.IsNaN(destination.Coordinates[0]) || math.IsInf(destination.Coordinates[0], 0) {
+		t.Errorf("Expected a finite longitude leaving the pole, got %v", destination.Coordinates)
+	}
+}
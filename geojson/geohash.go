@@ -0,0 +1,178 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the longest geohash supported, which resolves
+// positions to a few centimetres
+const MaxGeohashPrecision = 12
+
+// GeohashEncode returns the geohash of the given number of characters
+// for the cell containing the point
+func GeohashEncode(point *Point, precision int) (string, error) {
+	if point == nil || len(point.Coordinates) < 2 {
+		return "", errors.New("Geohash requires a point with a longitude and latitude")
+	}
+	if precision < 1 || precision > MaxGeohashPrecision {
+		return "", fmt.Errorf("Geohash precision %v must be between 1 and %v", precision, MaxGeohashPrecision)
+	}
+	longitude, latitude := point.Coordinates[0], point.Coordinates[1]
+	if math.Abs(longitude) > 180 || math.Abs(latitude) > 90 || math.IsNaN(longitude) || math.IsNaN(latitude) {
+		return "", fmt.Errorf("Position %v is not a longitude and latitude", point.Coordinates)
+	}
+
+	var (
+		result     strings.Builder
+		west, east = -180.0, 180.0
+		south      = -90.0
+		north      = 90.0
+		even       = true
+	)
+	for result.Len() < precision {
+		index := 0
+		for bit := 4; bit >= 0; bit-- {
+			if even {
+				if middle := (west + east) / 2; longitude >= middle {
+					index |= 1 << uint(bit)
+					west = middle
+				} else {
+					east = middle
+				}
+			} else {
+				if middle := (south + north) / 2; latitude >= middle {
+					index |= 1 << uint(bit)
+					south = middle
+				} else {
+					north = middle
+				}
+			}
+			even = !even
+		}
+		result.WriteByte(geohashAlphabet[index])
+	}
+	return result.String(), nil
+}
+
+// GeohashDecode returns the bounding box of the cell identified by a geohash
+func GeohashDecode(hash string) (BoundingBox, error) {
+	var (
+		west, east = -180.0, 180.0
+		south      = -90.0
+		north      = 90.0
+		even       = true
+	)
+	if hash == "" {
+		return nil, errors.New("Geohash must not be empty")
+	}
+	for _, character := range strings.ToLower(hash) {
+		index := strings.IndexRune(geohashAlphabet, character)
+		if index < 0 {
+			return nil, fmt.Errorf("Geohash %v contains the invalid character %q", hash, character)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := index&(1<<uint(bit)) != 0
+			if even {
+				if middle := (west + east) / 2; set {
+					west = middle
+				} else {
+					east = middle
+				}
+			} else {
+				if middle := (south + north) / 2; set {
+					south = middle
+				} else {
+					north = middle
+				}
+			}
+			even = !even
+		}
+	}
+	return BoundingBox{west, south, east, north}, nil
+}
+
+// GeohashNeighbours returns the geohashes of the eight cells around a geohash,
+// in the order north, northeast, east, southeast, south, southwest, west, northwest.
+// Neighbours wrap across the antimeridian; those beyond a pole are omitted.
+func GeohashNeighbours(hash string) ([]string, error) {
+	bbox, err := GeohashDecode(hash)
+	if err != nil {
+		return nil, err
+	}
+	width, height := bbox[2]-bbox[0], bbox[3]-bbox[1]
+	centerX, centerY := (bbox[0]+bbox[2])/2, (bbox[1]+bbox[3])/2
+	offsets := [][2]float64{{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1}}
+	var result []string
+	for _, offset := range offsets {
+		latitude := centerY + offset[1]*height
+		if math.Abs(latitude) > 90 {
+			continue
+		}
+		longitude := normalizeLongitude(centerX + offset[0]*width)
+		neighbour, err := GeohashEncode(NewPoint([]float64{longitude, latitude}), len(hash))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, neighbour)
+	}
+	return result, nil
+}
+
+// GeohashCover returns the sorted geohashes of the cells covering a Polygon or
+// MultiPolygon. Cells that lie entirely inside it are returned at the coarsest
+// precision that does so, while cells on its boundary are returned at the
+// precision requested; every covered position has one of the results as a prefix.
+func GeohashCover(geometry interface{}, precision int) ([]string, error) {
+	switch geometry.(type) {
+	case *Polygon, *MultiPolygon:
+	default:
+		return nil, fmt.Errorf("Geohash cover requires a Polygon or MultiPolygon, not %T", geometry)
+	}
+	if precision < 1 || precision > MaxGeohashPrecision {
+		return nil, fmt.Errorf("Geohash precision %v must be between 1 and %v", precision, MaxGeohashPrecision)
+	}
+	var (
+		result []string
+		cover  func(prefix string)
+	)
+	cover = func(prefix string) {
+		for _, character := range geohashAlphabet {
+			hash := prefix + string(character)
+			bbox, _ := GeohashDecode(hash)
+			cell := bbox.Geometry()
+			if !Intersects(geometry, cell) {
+				continue
+			}
+			if len(hash) == precision || Contains(geometry, cell) {
+				result = append(result, hash)
+				continue
+			}
+			cover(hash)
+		}
+	}
+	cover("")
+	sort.Strings(result)
+	return result, nil
+}
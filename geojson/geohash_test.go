@@ -0,0 +1,117 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestGeohashEncodeDecode(t *testing.T) {
+	hash, err := GeohashEncode(NewPoint([]float64{-5.6, 42.6}), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != "ezs42" {
+		t.Errorf("Expected ezs42, got %v", hash)
+	}
+	bbox, err := GeohashDecode("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := BoundingBox{-5.625, 42.5830078125, -5.5810546875, 42.626953125}
+	if !EqualsWithin(bbox.Geometry(), expected.Geometry(), 1e-12) {
+		t.Errorf("Expected %v, got %v", expected, bbox)
+	}
+	if !bbox.ContainsPoint(NewPoint([]float64{-5.6, 42.6})) {
+		t.Errorf("Expected %v to contain the encoded point", bbox)
+	}
+	if upper, _ := GeohashDecode("EZS42"); !upper.Equals(bbox) {
+		t.Errorf("Expected upper case geohashes to decode, got %v", upper)
+	}
+
+	if _, err = GeohashEncode(NewPoint([]float64{0, 0}), 13); err == nil {
+		t.Error("Expected an error for too much precision")
+	}
+	if _, err = GeohashEncode(NewPoint([]float64{0, 100}), 5); err == nil {
+		t.Error("Expected an error for an invalid latitude")
+	}
+	if _, err = GeohashDecode("ezs4a"); err == nil {
+		t.Error("Expected an error for an invalid character")
+	}
+}
+
+func TestGeohashNeighbours(t *testing.T) {
+	neighbours, err := GeohashNeighbours("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "ezs48 ezs49 ezs43 ezs41 ezs40 ezefp ezefr ezefx"
+	if strings.Join(neighbours, " ") != expected {
+		t.Errorf("Expected %v, got %v", expected, neighbours)
+	}
+
+	// Cells at the top right of the world wrap east and have nothing to the north
+	neighbours, _ = GeohashNeighbours("z")
+	expected = "b 8 x w y"
+	if strings.Join(neighbours, " ") != expected {
+		t.Errorf("Expected %v, got %v", expected, neighbours)
+	}
+}
+
+func TestGeohashCover(t *testing.T) {
+	polygon := NewPolygon([][][]float64{{{-5, 45}, {5, 45}, {3, 55}, {-5, 52}, {-5, 45}}})
+	cover, err := GeohashCover(polygon, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cover) == 0 {
+		t.Fatal("Expected a cover")
+	}
+	var coarser bool
+	for _, hash := range cover {
+		bbox, _ := GeohashDecode(hash)
+		if !Intersects(polygon, bbox.Geometry()) {
+			t.Errorf("Cell %v does not intersect the polygon", hash)
+		}
+		coarser = coarser || len(hash) < 4
+	}
+	if !coarser {
+		t.Error("Expected interior cells at a coarser precision")
+	}
+
+	random := rand.New(rand.NewSource(7))
+	for inx := 0; inx < 1000; inx++ {
+		point := NewPoint([]float64{random.Float64()*10 - 5, random.Float64()*10 + 45})
+		if !Contains(polygon, point) {
+			continue
+		}
+		hash, _ := GeohashEncode(point, 8)
+		found := false
+		for _, prefix := range cover {
+			found = found || strings.HasPrefix(hash, prefix)
+		}
+		if !found {
+			t.Errorf("Point %v (%v) is not covered", point.Coordinates, hash)
+		}
+	}
+
+	if _, err = GeohashCover(NewPoint([]float64{0, 0}), 4); err == nil {
+		t.Error("Expected an error for a Point")
+	}
+}
@@ -107,18 +107,49 @@ func FromMap(input map[string]interface{}) interface{} {
 	return nil
 }
 
+// WriteOption is an option for Write and WriteFile
+type WriteOption func(*writeSettings)
+
+type writeSettings struct {
+	fixed    bool
+	decimals int
+}
+
+// WithPrecision writes the coordinates and bounding boxes of GeoJSON objects
+// with exactly the number of decimal places provided
+func WithPrecision(decimals int) WriteOption {
+	return func(settings *writeSettings) {
+		settings.fixed = true
+		settings.decimals = decimals
+	}
+}
+
 // Write writes a GeoJSON object into a byte array
-func Write(input interface{}) ([]byte, error) {
-	return json.Marshal(input)
+func Write(input interface{}, options ...WriteOption) ([]byte, error) {
+	var settings writeSettings
+	for _, option := range options {
+		option(&settings)
+	}
+	if !settings.fixed {
+		return json.Marshal(input)
+	}
+	if settings.decimals < 0 {
+		return nil, fmt.Errorf("Cannot write %v decimal places", settings.decimals)
+	}
+	fixed, err := fixedPrecision(input, settings.decimals)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fixed)
 }
 
 // WriteFile writes a GeoJSON object to the file specified
-func WriteFile(input interface{}, filename string) error {
+func WriteFile(input interface{}, filename string, options ...WriteOption) error {
 	var (
 		bytes []byte
 		err   error
 	)
-	if bytes, err = Write(input); err != nil {
+	if bytes, err = Write(input, options...); err != nil {
 		return err
 	}
 	return ioutil.WriteFile(filename, bytes, 0666)
@@ -19,6 +19,7 @@ package geojson
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 )
@@ -110,6 +111,11 @@ func (point Point) WKT() string {
 	return result
 }
 
+// Clone returns a deep copy of the Point
+func (point Point) Clone() *Point {
+	return &Point{Type: point.Type, Coordinates: clone1(point.Coordinates), Bbox: point.Bbox.clone()}
+}
+
 // The LineString object contains a array of two or more positions
 type LineString struct {
 	Type        string      `json:"type"`
@@ -172,6 +178,11 @@ func (ls LineString) WKT() string {
 	return result
 }
 
+// Clone returns a deep copy of the LineString
+func (ls LineString) Clone() *LineString {
+	return &LineString{Type: ls.Type, Coordinates: clone2(ls.Coordinates), Bbox: ls.Bbox.clone()}
+}
+
 // The Polygon object contains a array of one or more linear rings
 type Polygon struct {
 	Type        string        `json:"type"`
@@ -234,6 +245,11 @@ func (polygon Polygon) WKT() string {
 	return result
 }
 
+// Clone returns a deep copy of the Polygon
+func (polygon Polygon) Clone() *Polygon {
+	return &Polygon{Type: polygon.Type, Coordinates: clone3(polygon.Coordinates), Bbox: polygon.Bbox.clone()}
+}
+
 // The MultiPoint object contains a array of one or more points
 type MultiPoint struct {
 	Type        string      `json:"type"`
@@ -296,6 +312,11 @@ func (mp MultiPoint) WKT() string {
 	return result
 }
 
+// Clone returns a deep copy of the MultiPoint
+func (mp MultiPoint) Clone() *MultiPoint {
+	return &MultiPoint{Type: mp.Type, Coordinates: clone2(mp.Coordinates), Bbox: mp.Bbox.clone()}
+}
+
 // The MultiLineString object contains a array of one or more line strings
 type MultiLineString struct {
 	Type        string        `json:"type"`
@@ -358,6 +379,11 @@ func (mls MultiLineString) WKT() string {
 	return result
 }
 
+// Clone returns a deep copy of the MultiLineString
+func (mls MultiLineString) Clone() *MultiLineString {
+	return &MultiLineString{Type: mls.Type, Coordinates: clone3(mls.Coordinates), Bbox: mls.Bbox.clone()}
+}
+
 // The MultiPolygon object contains a array of one or more polygons
 type MultiPolygon struct {
 	Type        string          `json:"type"`
@@ -420,6 +446,11 @@ func (mp MultiPolygon) WKT() string {
 	return result
 }
 
+// Clone returns a deep copy of the MultiPolygon
+func (mp MultiPolygon) Clone() *MultiPolygon {
+	return &MultiPolygon{Type: mp.Type, Coordinates: clone4(mp.Coordinates), Bbox: mp.Bbox.clone()}
+}
+
 // The GeometryCollection object contains a array of one or more polygons
 type GeometryCollection struct {
 	Type       string        `json:"type"`
@@ -491,6 +522,82 @@ func NewGeometryCollection(geometries []interface{}) *GeometryCollection {
 	return &GeometryCollection{Type: GEOMETRYCOLLECTION, Geometries: geometries}
 }
 
+// Clone returns a deep copy of the GeometryCollection
+func (gc GeometryCollection) Clone() *GeometryCollection {
+	var geometries []interface{}
+	if gc.Geometries != nil {
+		geometries = make([]interface{}, len(gc.Geometries))
+		for inx, geometry := range gc.Geometries {
+			geometries[inx] = cloneGeometry(geometry)
+		}
+	}
+	return &GeometryCollection{Type: gc.Type, Geometries: geometries, Bbox: gc.Bbox.clone()}
+}
+
+// cloneGeometry returns a deep copy of a geometry object
+func cloneGeometry(input interface{}) interface{} {
+	if value := reflect.ValueOf(input); value.Kind() == reflect.Ptr && value.IsNil() {
+		return input
+	}
+	switch it := input.(type) {
+	case *Point:
+		return it.Clone()
+	case *LineString:
+		return it.Clone()
+	case *Polygon:
+		return it.Clone()
+	case *MultiPoint:
+		return it.Clone()
+	case *MultiLineString:
+		return it.Clone()
+	case *MultiPolygon:
+		return it.Clone()
+	case *GeometryCollection:
+		return it.Clone()
+	}
+	return cloneValue(input)
+}
+
+func clone1(input []float64) []float64 {
+	if input == nil {
+		return nil
+	}
+	return append([]float64{}, input...)
+}
+
+func clone2(input [][]float64) [][]float64 {
+	if input == nil {
+		return nil
+	}
+	result := make([][]float64, len(input))
+	for inx, curr := range input {
+		result[inx] = clone1(curr)
+	}
+	return result
+}
+
+func clone3(input [][][]float64) [][][]float64 {
+	if input == nil {
+		return nil
+	}
+	result := make([][][]float64, len(input))
+	for inx, curr := range input {
+		result[inx] = clone2(curr)
+	}
+	return result
+}
+
+func clone4(input [][][][]float64) [][][][]float64 {
+	if input == nil {
+		return nil
+	}
+	result := make([][][][]float64, len(input))
+	for inx, curr := range input {
+		result[inx] = clone3(curr)
+	}
+	return result
+}
+
 // This quasi-recursive function determines drills into the
 // multidimensional array of interfaces to build a proper
 // coordinate array of the right dimension
@@ -569,32 +676,10 @@ func newGeometry(input interface{}) interface{} {
 }
 
 func interfaceTo2DArray(gjObject interface{}) [][]float64 {
-	var (
-		result [][]float64
-	)
-	switch typedGJ := gjObject.(type) {
-	case *Point:
-		result = append(result, typedGJ.Coordinates)
-	case *LineString:
-		result = typedGJ.Coordinates
-	case *MultiPoint:
-		result = typedGJ.Coordinates
-	case *MultiLineString:
-		for _, c3 := range typedGJ.Coordinates {
-			result = append(result, c3...)
-		}
-	case *Polygon:
-		for _, c3 := range typedGJ.Coordinates {
-			result = append(result, c3...)
-		}
-	case *Feature:
-		result = interfaceTo2DArray(typedGJ.Geometry)
-	case *FeatureCollection:
-		for _, feature := range typedGJ.Features {
-			result = append(result, interfaceTo2DArray(feature)...)
-		}
-	}
-
+	var result [][]float64
+	EachCoordinate(gjObject, func(position []float64) {
+		result = append(result, position)
+	})
 	return result
 }
 
@@ -0,0 +1,311 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// GPX property names
+const (
+	GPXTIME  = "time"
+	GPXTIMES = "times"
+)
+
+const gpxNamespace = "http://www.topografix.com/GPX/1/1"
+
+type gpxDocument struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	Namespace string     `xml:"xmlns,attr,omitempty"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []gpxRoute `xml:"rte"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+// gpxDescription holds the elements common to waypoints, routes and tracks,
+// which become properties of the same names
+type gpxDescription struct {
+	Name string `xml:"name,omitempty"`
+	Cmt  string `xml:"cmt,omitempty"`
+	Desc string `xml:"desc,omitempty"`
+}
+
+type gpxPoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Ele  string `xml:"ele,omitempty"`
+	Time string `xml:"time,omitempty"`
+	gpxDescription
+	Sym  string `xml:"sym,omitempty"`
+	Type string `xml:"type,omitempty"`
+}
+
+type gpxRoute struct {
+	gpxDescription
+	Type   string     `xml:"type,omitempty"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	gpxDescription
+	Type     string       `xml:"type,omitempty"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+// FromGPX constructs a FeatureCollection from a GPX 1.1 document.
+// Waypoints become Points, routes become LineStrings and tracks become
+// MultiLineStrings with a line for each segment, with any elevations as
+// the third ordinate. The name, cmt, desc, sym and type elements become
+// properties. A waypoint's time is its "time" property, and the times of
+// the points of a route or track are in its "times" property, in the same
+// arrangement as its coordinates, with "" for points without a time.
+func FromGPX(input []byte) (*FeatureCollection, error) {
+	var document gpxDocument
+	if err := xml.Unmarshal(input, &document); err != nil {
+		return nil, err
+	}
+	features := []*Feature{}
+	for _, waypoint := range document.Waypoints {
+		position, err := waypoint.position()
+		if err != nil {
+			return nil, err
+		}
+		properties := waypoint.gpxDescription.properties(waypoint.Type)
+		if waypoint.Sym != "" {
+			properties["sym"] = waypoint.Sym
+		}
+		if waypoint.Time != "" {
+			properties[GPXTIME] = waypoint.Time
+		}
+		features = append(features, NewFeature(NewPoint(position), nil, properties))
+	}
+	for _, route := range document.Routes {
+		line, times, err := gpxLine(route.Points)
+		if err != nil {
+			return nil, err
+		}
+		properties := route.gpxDescription.properties(route.Type)
+		if times != nil {
+			properties[GPXTIMES] = times
+		}
+		features = append(features, NewFeature(NewLineString(line), nil, properties))
+	}
+	for _, track := range document.Tracks {
+		var (
+			lines    = [][][]float64{}
+			times    = []interface{}{}
+			hasTimes bool
+		)
+		for _, segment := range track.Segments {
+			line, segmentTimes, err := gpxLine(segment.Points)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, line)
+			if segmentTimes == nil {
+				segmentTimes = make([]interface{}, len(line))
+				for inx := range segmentTimes {
+					segmentTimes[inx] = ""
+				}
+			} else {
+				hasTimes = true
+			}
+			times = append(times, segmentTimes)
+		}
+		properties := track.gpxDescription.properties(track.Type)
+		if hasTimes {
+			properties[GPXTIMES] = times
+		}
+		features = append(features, NewFeature(NewMultiLineString(lines), nil, properties))
+	}
+	return NewFeatureCollection(features), nil
+}
+
+func (description gpxDescription) properties(gpxType string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range map[string]string{"name": description.Name, "cmt": description.Cmt, "desc": description.Desc, "type": gpxType} {
+		if value != "" {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func (point gpxPoint) position() ([]float64, error) {
+	lon, lonErr := strconv.ParseFloat(point.Lon, 64)
+	lat, latErr := strconv.ParseFloat(point.Lat, 64)
+	if lonErr != nil || latErr != nil {
+		return nil, fmt.Errorf("Invalid GPX coordinates lat=%q lon=%q", point.Lat, point.Lon)
+	}
+	if point.Ele == "" {
+		return []float64{lon, lat}, nil
+	}
+	ele, err := strconv.ParseFloat(point.Ele, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid GPX elevation %q", point.Ele)
+	}
+	return []float64{lon, lat, ele}, nil
+}
+
+// gpxLine returns the positions of the points and their times,
+// or nil times if none of the points has one
+func gpxLine(points []gpxPoint) ([][]float64, []interface{}, error) {
+	var (
+		line     = [][]float64{}
+		times    = make([]interface{}, len(points))
+		hasTimes bool
+	)
+	for inx, point := range points {
+		position, err := point.position()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, position)
+		times[inx] = point.Time
+		hasTimes = hasTimes || point.Time != ""
+	}
+	if !hasTimes {
+		times = nil
+	}
+	return line, times, nil
+}
+
+// ToGPX writes a FeatureCollection as a GPX 1.1 document.
+// Points and MultiPoints become waypoints, LineStrings become routes and
+// MultiLineStrings become tracks, the reverse of FromGPX; the members of
+// GeometryCollections are written in turn and other geometries are ignored.
+func ToGPX(fc *FeatureCollection) ([]byte, error) {
+	document := gpxDocument{
+		Namespace: gpxNamespace,
+		Version:   "1.1",
+		Creator:   "github.com/venicegeo/geojson-go"}
+	if fc != nil {
+		for _, feature := range fc.Features {
+			if feature != nil {
+				document.add(feature, feature.resolvedGeometry())
+			}
+		}
+	}
+	result, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), result...), nil
+}
+
+func (document *gpxDocument) add(feature *Feature, geometry interface{}) {
+	description := gpxDescription{
+		Name: feature.PropertyString("name"),
+		Cmt:  feature.PropertyString("cmt"),
+		Desc: feature.PropertyString("desc")}
+	gpxType := feature.PropertyString("type")
+	switch typed := geometry.(type) {
+	case *Point:
+		document.addWaypoint(feature, description, typed.Coordinates)
+	case *MultiPoint:
+		for _, position := range typed.Coordinates {
+			document.addWaypoint(feature, description, position)
+		}
+	case *LineString:
+		document.Routes = append(document.Routes, gpxRoute{
+			gpxDescription: description,
+			Type:           gpxType,
+			Points:         newGPXPoints(typed.Coordinates, feature.Properties[GPXTIMES])})
+	case *MultiLineString:
+		track := gpxTrack{gpxDescription: description, Type: gpxType}
+		times := gpxTimes(feature.Properties[GPXTIMES])
+		for inx, line := range typed.Coordinates {
+			var segmentTimes interface{}
+			if inx < len(times) {
+				segmentTimes = times[inx]
+			}
+			track.Segments = append(track.Segments, gpxSegment{newGPXPoints(line, segmentTimes)})
+		}
+		document.Tracks = append(document.Tracks, track)
+	case *GeometryCollection:
+		for _, curr := range typed.Geometries {
+			document.add(feature, curr)
+		}
+	}
+}
+
+func (document *gpxDocument) addWaypoint(feature *Feature, description gpxDescription, position []float64) {
+	if len(position) < 2 {
+		return
+	}
+	waypoint := newGPXPoint(position, feature.PropertyString(GPXTIME))
+	waypoint.gpxDescription = description
+	waypoint.Sym = feature.PropertyString("sym")
+	waypoint.Type = feature.PropertyString("type")
+	document.Waypoints = append(document.Waypoints, waypoint)
+}
+
+func newGPXPoint(position []float64, time string) gpxPoint {
+	result := gpxPoint{
+		Lat:  strconv.FormatFloat(position[1], 'f', -1, 64),
+		Lon:  strconv.FormatFloat(position[0], 'f', -1, 64),
+		Time: time}
+	if len(position) > 2 {
+		result.Ele = strconv.FormatFloat(position[2], 'f', -1, 64)
+	}
+	return result
+}
+
+func newGPXPoints(line [][]float64, times interface{}) []gpxPoint {
+	timeValues := gpxTimes(times)
+	var result []gpxPoint
+	for inx, position := range line {
+		if len(position) < 2 {
+			continue
+		}
+		var time string
+		if inx < len(timeValues) {
+			time = stringify(timeValues[inx])
+		}
+		result = append(result, newGPXPoint(position, time))
+	}
+	return result
+}
+
+// gpxTimes returns the members of a times property
+func gpxTimes(times interface{}) []interface{} {
+	switch typed := times.(type) {
+	case []interface{}:
+		return typed
+	case []string:
+		result := make([]interface{}, len(typed))
+		for inx, value := range typed {
+			result[inx] = value
+		}
+		return result
+	case [][]string:
+		result := make([]interface{}, len(typed))
+		for inx, value := range typed {
+			result[inx] = value
+		}
+		return result
+	}
+	return nil
+}
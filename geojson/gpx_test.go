@@ -0,0 +1,127 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"strings"
+	"testing"
+)
+
+const gpxSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="38.5" lon="-77.25">
+    <ele>12.5</ele>
+    <time>2024-05-01T12:00:00Z</time>
+    <name>Camp</name>
+    <sym>Flag</sym>
+  </wpt>
+  <rte>
+    <name>Plan</name>
+    <rtept lat="1" lon="2"/>
+    <rtept lat="3" lon="4"/>
+  </rte>
+  <trk>
+    <name>Walk</name>
+    <type>hiking</type>
+    <trkseg>
+      <trkpt lat="10" lon="20"><ele>100</ele><time>2024-05-01T12:00:00Z</time></trkpt>
+      <trkpt lat="11" lon="21"><ele>101</ele><time>2024-05-01T12:01:00Z</time></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="12" lon="22"><ele>102</ele></trkpt>
+      <trkpt lat="13" lon="23"><ele>103</ele><time>2024-05-01T12:03:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestFromGPX(t *testing.T) {
+	fc, err := FromGPX([]byte(gpxSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-77.25,38.5,12.5]},"properties":{"name":"Camp","sym":"Flag","time":"2024-05-01T12:00:00Z"}}`,
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[2,1],[4,3]]},"properties":{"name":"Plan"}}`,
+		`{"type":"Feature","geometry":{"type":"MultiLineString","coordinates":[[[20,10,100],[21,11,101]],[[22,12,102],[23,13,103]]]},` +
+			`"properties":{"name":"Walk","times":[["2024-05-01T12:00:00Z","2024-05-01T12:01:00Z"],["","2024-05-01T12:03:00Z"]],"type":"hiking"}}`,
+	}
+	if len(fc.Features) != len(expected) {
+		t.Fatalf("Expected %v features, got %v", len(expected), fc.String())
+	}
+	for inx, feature := range fc.Features {
+		if actual := feature.String(); actual != expected[inx] {
+			t.Errorf("Expected %v, got %v", expected[inx], actual)
+		}
+	}
+
+	for _, input := range []string{
+		`<gpx><wpt lat="a" lon="1"/></gpx>`,
+		`<gpx><rte><rtept lat="1" lon="1"><ele>high</ele></rtept></rte></gpx>`,
+		`<gpx><trk><trkseg><trkpt lat="1"/></trkseg></trk></gpx>`,
+		`<gpx>`,
+	} {
+		if _, err = FromGPX([]byte(input)); err == nil {
+			t.Errorf("Expected an error for %v", input)
+		}
+	}
+}
+
+func TestToGPX(t *testing.T) {
+	fc, err := FromGPX([]byte(gpxSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fc.Features = append(fc.Features,
+		NewFeature(NewMultiPoint([][]float64{{0.00001, 1}, {2, 3}}), nil, map[string]interface{}{"name": "Pair"}),
+		NewFeature(NewLineString([][]float64{{5, 6}, {7, 8}}), nil, map[string]interface{}{"times": []string{"a", "b"}}),
+		NewFeature(NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}), nil, nil))
+
+	data, err := ToGPX(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, expected := range []string{
+		`<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1"`,
+		`<wpt lat="38.5" lon="-77.25">`,
+		`<wpt lat="1" lon="0.00001">`,
+		`<rtept lat="8" lon="7">`, `<time>b</time>`,
+		`<type>hiking</type>`,
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %v in %v", expected, text)
+		}
+	}
+
+	result, err := FromGPX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The MultiPoint becomes two waypoints and the Polygon is dropped
+	if len(result.Features) != 6 {
+		t.Fatalf("Expected 6 features, got %v", result.String())
+	}
+	// Waypoints come first, then routes, then tracks
+	for inx, expected := range map[int]*Feature{0: fc.Features[0], 3: fc.Features[1], 4: fc.Features[4], 5: fc.Features[2]} {
+		if actual := result.Features[inx].String(); actual != expected.String() {
+			t.Errorf("Expected %v, got %v", expected.String(), actual)
+		}
+	}
+	if name := result.Features[2].PropertyString("name"); name != "Pair" {
+		t.Errorf("Expected each waypoint to keep the properties, got %v", name)
+	}
+}
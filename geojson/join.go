@@ -0,0 +1,186 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// JoinPredicate selects which right Features match a left Feature in a spatial join
+type JoinPredicate int
+
+const (
+	// JoinIntersects matches right Features that intersect the left Feature
+	JoinIntersects JoinPredicate = iota
+	// JoinWithin matches right Features that the left Feature lies within
+	JoinWithin
+	// JoinContains matches right Features that the left Feature contains
+	JoinContains
+	// JoinNearest matches the single nearest right Feature within MaxDistance
+	JoinNearest
+)
+
+// JoinMode selects what happens to left Features without a match
+type JoinMode int
+
+const (
+	// LeftJoin keeps left Features without a match, with only their own properties
+	LeftJoin JoinMode = iota
+	// InnerJoin drops left Features without a match
+	InnerJoin
+)
+
+// JoinOptions configures SpatialJoin
+type JoinOptions struct {
+	Predicate JoinPredicate
+	Mode      JoinMode
+	// MaxDistance limits JoinNearest; zero means no limit
+	MaxDistance float64
+	// DistanceMode is how JoinNearest measures distance
+	DistanceMode DistanceMode
+	// LeftPrefix and RightPrefix are prepended to the property names from each side.
+	// Where names still collide, the left value is kept.
+	LeftPrefix  string
+	RightPrefix string
+}
+
+// SpatialJoin returns a FeatureCollection with a Feature for each pair of
+// matching left and right Features. Each has a copy of the left Feature's
+// geometry and ID and the properties of both. A left Feature matching several
+// right Features appears once for each, in the order of the right collection.
+func SpatialJoin(left, right *FeatureCollection, options JoinOptions) *FeatureCollection {
+	features := []*Feature{}
+	if left == nil {
+		return NewFeatureCollection(features)
+	}
+	if right == nil {
+		right = NewFeatureCollection(nil)
+	}
+	tree := NewRTree(right)
+	order := make(map[*Feature]int, len(right.Features))
+	for inx, feature := range right.Features {
+		order[feature] = inx
+	}
+
+	for _, leftFeature := range left.Features {
+		if leftFeature == nil {
+			continue
+		}
+		var matches []*Feature
+		if leftFeature.Geometry != nil {
+			if options.Predicate == JoinNearest {
+				matches = nearestMatch(leftFeature, right, tree, options)
+			} else {
+				matches = predicateMatches(leftFeature, tree, options.Predicate)
+			}
+		}
+		sort.Slice(matches, func(i, j int) bool {
+			return order[matches[i]] < order[matches[j]]
+		})
+		if len(matches) == 0 && options.Mode == LeftJoin {
+			features = append(features, joinFeature(leftFeature, nil, options))
+		}
+		for _, match := range matches {
+			features = append(features, joinFeature(leftFeature, match, options))
+		}
+	}
+	return NewFeatureCollection(features)
+}
+
+func predicateMatches(leftFeature *Feature, tree *RTree, predicate JoinPredicate) []*Feature {
+	var result []*Feature
+	for _, candidate := range tree.Search(leftFeature.resolvedBbox()) {
+		var matched bool
+		switch predicate {
+		case JoinWithin:
+			matched = Within(leftFeature, candidate)
+		case JoinContains:
+			matched = Contains(leftFeature, candidate)
+		default:
+			matched = Intersects(leftFeature, candidate)
+		}
+		if matched {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// nearestMatch returns the nearest right Feature within the maximum distance, if any
+func nearestMatch(leftFeature *Feature, right *FeatureCollection, tree *RTree, options JoinOptions) []*Feature {
+	candidates := right.Features
+	if options.MaxDistance > 0 {
+		candidates = tree.Search(expandBbox(leftFeature.resolvedBbox(), options.MaxDistance, options.DistanceMode))
+	}
+	var (
+		nearest *Feature
+		best    = math.Inf(1)
+	)
+	for _, candidate := range candidates {
+		if candidate == nil {
+			continue
+		}
+		distance, _, _ := Distance(leftFeature, candidate, options.DistanceMode)
+		if distance < best && (options.MaxDistance <= 0 || distance <= options.MaxDistance) {
+			nearest, best = candidate, distance
+		}
+	}
+	if nearest == nil {
+		return nil
+	}
+	return []*Feature{nearest}
+}
+
+// expandBbox returns a two dimensional bounding box grown by the distance on
+// every side. In Geodesic mode the distance is in metres and the result
+// may cross the antimeridian.
+func expandBbox(bbox BoundingBox, distance float64, mode DistanceMode) BoundingBox {
+	if len(bbox) < 4 {
+		return bbox
+	}
+	half := len(bbox) / 2
+	west, south, east, north := bbox[0], bbox[1], bbox[half], bbox[half+1]
+	if mode != Geodesic {
+		return BoundingBox{west - distance, south - distance, east + distance, north + distance}
+	}
+	degrees := toDegrees(distance / EarthRadius)
+	south, north = south-degrees, north+degrees
+	if south <= -90 || north >= 90 {
+		// Every longitude is within reach of a pole
+		return BoundingBox{-180, math.Max(south, -90), 180, math.Min(north, 90)}
+	}
+	longitudes := degrees / math.Cos(toRadians(math.Max(math.Abs(south), math.Abs(north))))
+	if width := spanWidth(west, east) + 2*longitudes; width >= 360 {
+		return BoundingBox{-180, south, 180, north}
+	}
+	return BoundingBox{normalizeLongitude(west - longitudes), south, normalizeLongitude(east + longitudes), north}
+}
+
+// joinFeature returns a copy of the left Feature with the properties of both
+func joinFeature(leftFeature, rightFeature *Feature, options JoinOptions) *Feature {
+	properties := make(map[string]interface{})
+	if rightFeature != nil {
+		for key, value := range rightFeature.Properties {
+			properties[options.RightPrefix+key] = cloneValue(value)
+		}
+	}
+	for key, value := range leftFeature.Properties {
+		properties[options.LeftPrefix+key] = cloneValue(value)
+	}
+	return NewFeature(cloneGeometry(leftFeature.Geometry), cloneValue(leftFeature.ID), properties)
+}
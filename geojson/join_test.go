@@ -0,0 +1,146 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+func TestPredicates(t *testing.T) {
+	square := NewPolygon([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}})
+	holed := NewPolygon([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}}})
+	concave := NewPolygon([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {5, 2}, {0, 10}, {0, 0}}})
+	inside := NewPoint([]float64{5, 5})
+	edge := NewPoint([]float64{10, 5})
+	outside := NewPoint([]float64{15, 5})
+
+	testCases := []struct {
+		name      string
+		a, b      interface{}
+		intersect bool
+		contains  bool
+	}{
+		{"point inside", square, inside, true, true},
+		{"point on edge", square, edge, true, true},
+		{"point outside", square, outside, false, false},
+		{"point in hole", holed, inside, false, false},
+		{"line inside", square, NewLineString([][]float64{{1, 1}, {9, 9}}), true, true},
+		{"line crossing", square, NewLineString([][]float64{{5, 5}, {15, 5}}), true, false},
+		{"line across notch", concave, NewLineString([][]float64{{1, 8}, {9, 8}}), true, false},
+		{"polygon around hole", holed, NewPolygon([][][]float64{{{3, 3}, {7, 3}, {7, 7}, {3, 7}, {3, 3}}}), true, false},
+		{"polygon itself", square, square, true, true},
+		{"line contains point", NewLineString([][]float64{{0, 0}, {10, 10}}), inside, true, true},
+		{"line does not contain polygon", NewLineString([][]float64{{0, 0}, {10, 10}}), square, true, false},
+		{"points", NewMultiPoint([][]float64{{1, 1}, {2, 2}}), NewPoint([]float64{2, 2}), true, true},
+		{"empty", square, NewLineString([][]float64{}), false, false},
+		{"collection contains point", NewFeatureCollection([]*Feature{NewFeature(square, nil, nil), nil}), inside, true, true},
+		{"collection of points", NewFeatureCollection([]*Feature{NewFeature(outside, nil, nil), NewFeature(edge, nil, nil)}), square, true, false},
+	}
+	for _, testCase := range testCases {
+		if result := Intersects(testCase.a, testCase.b); result != testCase.intersect {
+			t.Errorf("%v: expected Intersects %v, got %v", testCase.name, testCase.intersect, result)
+		}
+		if result := Intersects(testCase.b, testCase.a); result != testCase.intersect {
+			t.Errorf("%v: expected Intersects to be symmetric", testCase.name)
+		}
+		if result := Contains(testCase.a, testCase.b); result != testCase.contains {
+			t.Errorf("%v: expected Contains %v, got %v", testCase.name, testCase.contains, result)
+		}
+		if result := Within(testCase.b, testCase.a); result != testCase.contains {
+			t.Errorf("%v: expected Within %v, got %v", testCase.name, testCase.contains, result)
+		}
+	}
+}
+
+func TestSpatialJoin(t *testing.T) {
+	regions := NewFeatureCollection([]*Feature{
+		NewFeature(NewPolygon([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}), "west", map[string]interface{}{"name": "West"}),
+		NewFeature(NewPolygon([][][]float64{{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}}), "east", map[string]interface{}{"name": "East"})})
+	detections := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{5, 5}), 1, map[string]interface{}{"name": "a"}),
+		NewFeature(NewPoint([]float64{10, 5}), 2, map[string]interface{}{"name": "b"}),
+		NewFeature(NewPoint([]float64{30, 5}), 3, map[string]interface{}{"name": "c"})})
+
+	options := JoinOptions{Predicate: JoinWithin, RightPrefix: "region_"}
+	result := SpatialJoin(detections, regions, options)
+	// b is on the shared edge, so it is within both
+	expected := []string{"a West", "b West", "b East", "c "}
+	if len(result.Features) != len(expected) {
+		t.Fatalf("Expected %v features, got %v", len(expected), result.String())
+	}
+	for inx, feature := range result.Features {
+		if actual := feature.PropertyString("name") + " " + stringify(feature.Properties["region_name"]); actual != expected[inx] {
+			t.Errorf("Expected %v, got %v", expected[inx], actual)
+		}
+	}
+	if result.Features[0].Geometry == detections.Features[0].Geometry || result.Features[0].ID != 1 {
+		t.Error("Expected a copy of the left feature")
+	}
+
+	options.Mode = InnerJoin
+	if result = SpatialJoin(detections, regions, options); len(result.Features) != 3 {
+		t.Errorf("Expected 3 features, got %v", result.String())
+	}
+
+	options = JoinOptions{Predicate: JoinContains, Mode: InnerJoin, LeftPrefix: "left_", RightPrefix: "right_"}
+	result = SpatialJoin(regions, detections, options)
+	if len(result.Features) != 3 || result.Features[0].PropertyString("right_name") != "a" ||
+		result.Features[0].PropertyString("left_name") != "West" {
+		t.Errorf("Unexpected join %v", result.String())
+	}
+
+	// The left value wins when names collide
+	result = SpatialJoin(detections, regions, JoinOptions{Predicate: JoinIntersects, Mode: InnerJoin})
+	if result.Features[0].PropertyString("name") != "a" {
+		t.Errorf("Expected the left property to be kept, got %v", result.Features[0].String())
+	}
+
+	options = JoinOptions{Predicate: JoinNearest, MaxDistance: 15, RightPrefix: "region_"}
+	result = SpatialJoin(detections, regions, options)
+	if len(result.Features) != 3 || result.Features[1].PropertyString("region_name") != "West" ||
+		result.Features[2].PropertyString("region_name") != "East" {
+		t.Errorf("Unexpected nearest join %v", result.String())
+	}
+	options.MaxDistance = 5
+	if result = SpatialJoin(detections, regions, options); result.Features[2].Properties["region_name"] != nil {
+		t.Errorf("Expected c to be out of range, got %v", result.Features[2].String())
+	}
+}
+
+func TestSpatialJoinGeodesic(t *testing.T) {
+	stations := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{179.9, 0}), "dateline", map[string]interface{}{"name": "Dateline"}),
+		NewFeature(NewPoint([]float64{0, 0}), "greenwich", map[string]interface{}{"name": "Greenwich"})})
+	sightings := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{-179.9, 0}), "s", nil)})
+
+	// About 22 km away across the antimeridian
+	options := JoinOptions{Predicate: JoinNearest, Mode: InnerJoin, MaxDistance: 25000, DistanceMode: Geodesic}
+	result := SpatialJoin(sightings, stations, options)
+	if len(result.Features) != 1 || result.Features[0].PropertyString("name") != "Dateline" {
+		t.Errorf("Expected the dateline station, got %v", result.String())
+	}
+	options.MaxDistance = 20000
+	if result = SpatialJoin(sightings, stations, options); len(result.Features) != 0 {
+		t.Errorf("Expected no match, got %v", result.String())
+	}
+
+	expanded := expandBbox(BoundingBox{179, 10, 179.5, 11}, 200000, Geodesic)
+	if !expanded.Antimeridian() {
+		t.Errorf("Expected the expanded bbox to cross the antimeridian, got %v", expanded)
+	}
+}
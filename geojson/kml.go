@@ -0,0 +1,525 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KML property names
+const (
+	KMLNAME        = "name"
+	KMLDESCRIPTION = "description"
+	KMLFOLDER      = "folder"
+)
+
+// KMLOptions configures ToKML
+type KMLOptions struct {
+	// DocumentName is the name given to the KML Document, if any
+	DocumentName string
+	// Styles adds a Style to each Placemark from the "simplestyle" properties
+	// stroke, stroke-width, stroke-opacity, fill, fill-opacity and marker-color
+	Styles bool
+}
+
+type kmlDocument struct {
+	XMLName    xml.Name        `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Name       string          `xml:"Document>name,omitempty"`
+	Placemarks []*kmlPlacemark `xml:"Document>Placemark"`
+}
+
+type kmlPlacemark struct {
+	ID           string           `xml:"id,attr,omitempty"`
+	Name         string           `xml:"name,omitempty"`
+	Description  string           `xml:"description,omitempty"`
+	Style        *kmlStyle        `xml:"Style"`
+	ExtendedData *kmlExtendedData `xml:"ExtendedData"`
+	kmlMultiGeometry
+}
+
+// kmlMultiGeometry holds the elements of a Placemark or MultiGeometry
+// that are not otherwise decoded, in document order. Those that are not
+// geometries are ignored.
+type kmlMultiGeometry struct {
+	Elements []kmlGeometry `xml:",any"`
+}
+
+// kmlGeometry is a Point, LineString, LinearRing, Polygon or MultiGeometry element
+type kmlGeometry struct {
+	XMLName     xml.Name
+	Coordinates string        `xml:"coordinates,omitempty"`
+	Outer       *kmlBoundary  `xml:"outerBoundaryIs"`
+	Inner       []kmlBoundary `xml:"innerBoundaryIs"`
+	kmlMultiGeometry
+}
+
+type kmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlBoundary struct {
+	LinearRing kmlCoordinates `xml:"LinearRing"`
+}
+
+type kmlExtendedData struct {
+	Data       []kmlData       `xml:"Data"`
+	SimpleData []kmlSimpleData `xml:"SchemaData>SimpleData"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlSimpleData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type kmlStyle struct {
+	IconStyle *kmlColorStyle `xml:"IconStyle"`
+	LineStyle *kmlLineStyle  `xml:"LineStyle"`
+	PolyStyle *kmlColorStyle `xml:"PolyStyle"`
+}
+
+type kmlColorStyle struct {
+	Color string `xml:"color"`
+}
+
+type kmlLineStyle struct {
+	Color string  `xml:"color,omitempty"`
+	Width float64 `xml:"width,omitempty"`
+}
+
+// kmlTupleSeparator matches the commas within a KML coordinate tuple,
+// which some writers surround with spaces
+var kmlTupleSeparator = regexp.MustCompile(`\s*,\s*`)
+
+// FromKML constructs a FeatureCollection from the Placemarks of a KML document.
+// The name and description of each Placemark and its ExtendedData become
+// properties, the latter as strings. Placemarks in named Folders have the
+// names of their Folders, separated by "/", as the "folder" property.
+// A MultiGeometry becomes a Multi geometry if its parts are all of one type,
+// or a GeometryCollection otherwise; LinearRings become LineStrings.
+// A Placemark with several geometries outside a MultiGeometry
+// has them as a GeometryCollection.
+func FromKML(input []byte) (*FeatureCollection, error) {
+	var (
+		elements []string
+		folders  []string
+		features = []*Feature{}
+	)
+	decoder := xml.NewDecoder(bytes.NewReader(input))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch typed := token.(type) {
+		case xml.StartElement:
+			switch {
+			case typed.Name.Local == "Placemark":
+				var placemark kmlPlacemark
+				if err = decoder.DecodeElement(&placemark, &typed); err != nil {
+					return nil, err
+				}
+				feature, err := placemark.feature()
+				if err != nil {
+					return nil, err
+				}
+				if folder := kmlFolderPath(folders); folder != "" {
+					feature.Properties[KMLFOLDER] = folder
+				}
+				features = append(features, feature)
+				continue
+			case typed.Name.Local == "Folder":
+				folders = append(folders, "")
+			case typed.Name.Local == "name" && len(elements) > 0 && elements[len(elements)-1] == "Folder":
+				var name string
+				if err = decoder.DecodeElement(&name, &typed); err != nil {
+					return nil, err
+				}
+				folders[len(folders)-1] = strings.TrimSpace(name)
+				continue
+			}
+			elements = append(elements, typed.Name.Local)
+		case xml.EndElement:
+			if typed.Name.Local == "Folder" {
+				folders = folders[:len(folders)-1]
+			}
+			elements = elements[:len(elements)-1]
+		}
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// kmlFolderPath returns the names of the Folders, skipping those without a name
+func kmlFolderPath(folders []string) string {
+	var names []string
+	for _, name := range folders {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+func (placemark *kmlPlacemark) feature() (*Feature, error) {
+	properties := make(map[string]interface{})
+	if placemark.ExtendedData != nil {
+		for _, data := range placemark.ExtendedData.Data {
+			properties[data.Name] = strings.TrimSpace(data.Value)
+		}
+		for _, data := range placemark.ExtendedData.SimpleData {
+			properties[data.Name] = strings.TrimSpace(data.Value)
+		}
+	}
+	if name := strings.TrimSpace(placemark.Name); name != "" {
+		properties[KMLNAME] = name
+	}
+	if description := strings.TrimSpace(placemark.Description); description != "" {
+		properties[KMLDESCRIPTION] = description
+	}
+	geometries, err := placemark.kmlMultiGeometry.geometries()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		geometry interface{}
+		id       interface{}
+	)
+	switch len(geometries) {
+	case 0:
+	case 1:
+		geometry = geometries[0]
+	default:
+		geometry = NewGeometryCollection(geometries)
+	}
+	if placemark.ID != "" {
+		id = placemark.ID
+	}
+	return NewFeature(geometry, id, properties), nil
+}
+
+// geometries returns the geometries of the element in document order,
+// with each MultiGeometry collapsed into a single geometry
+func (multi *kmlMultiGeometry) geometries() ([]interface{}, error) {
+	var result []interface{}
+	for _, element := range multi.Elements {
+		switch element.XMLName.Local {
+		case "Point":
+			positions, err := parseKMLCoordinates(element.Coordinates)
+			if err != nil {
+				return nil, err
+			}
+			if len(positions) > 0 {
+				result = append(result, NewPoint(positions[0]))
+			}
+		case "LineString", "LinearRing":
+			positions, err := parseKMLCoordinates(element.Coordinates)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, NewLineString(positions))
+		case "Polygon":
+			polygon, err := element.polygon()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, polygon)
+		case "MultiGeometry":
+			parts, err := element.geometries()
+			if err != nil {
+				return nil, err
+			}
+			if geometry := combineKMLGeometries(parts); geometry != nil {
+				result = append(result, geometry)
+			}
+		}
+	}
+	return result, nil
+}
+
+// polygon returns the Polygon of a Polygon element. A Polygon without
+// an outer boundary is empty, or an error if it has inner boundaries.
+func (element *kmlGeometry) polygon() (*Polygon, error) {
+	var outer [][]float64
+	if element.Outer != nil {
+		var err error
+		if outer, err = parseKMLCoordinates(element.Outer.LinearRing.Coordinates); err != nil {
+			return nil, err
+		}
+	}
+	rings := [][][]float64{}
+	if len(outer) > 0 {
+		rings = append(rings, outer)
+	}
+	for _, boundary := range element.Inner {
+		positions, err := parseKMLCoordinates(boundary.LinearRing.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		if len(positions) == 0 {
+			continue
+		}
+		if len(outer) == 0 {
+			return nil, fmt.Errorf("KML Polygon has an inner boundary but no outer boundary")
+		}
+		rings = append(rings, positions)
+	}
+	return NewPolygon(rings), nil
+}
+
+// combineKMLGeometries returns the geometry of a MultiGeometry's parts
+func combineKMLGeometries(parts []interface{}) interface{} {
+	switch len(parts) {
+	case 0:
+		return nil
+	case 1:
+		return parts[0]
+	}
+	var (
+		points   [][]float64
+		lines    [][][]float64
+		polygons [][][][]float64
+	)
+	for _, part := range parts {
+		switch typed := part.(type) {
+		case *Point:
+			points = append(points, typed.Coordinates)
+		case *LineString:
+			lines = append(lines, typed.Coordinates)
+		case *Polygon:
+			polygons = append(polygons, typed.Coordinates)
+		}
+	}
+	switch len(parts) {
+	case len(points):
+		return NewMultiPoint(points)
+	case len(lines):
+		return NewMultiLineString(lines)
+	case len(polygons):
+		return NewMultiPolygon(polygons)
+	}
+	return NewGeometryCollection(parts)
+}
+
+func parseKMLCoordinates(input string) ([][]float64, error) {
+	result := [][]float64{}
+	for _, tuple := range strings.Fields(kmlTupleSeparator.ReplaceAllString(input, ",")) {
+		values := strings.Split(tuple, ",")
+		if len(values) < 2 {
+			return nil, fmt.Errorf("Invalid KML coordinates %v", tuple)
+		}
+		position := make([]float64, len(values))
+		for inx, value := range values {
+			var err error
+			if position[inx], err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("Invalid KML coordinates %v", tuple)
+			}
+		}
+		result = append(result, position)
+	}
+	return result, nil
+}
+
+// ToKML writes a FeatureCollection as a KML document with a Placemark
+// for each Feature. The "name" and "description" properties become the
+// Placemark's name and description, and the other properties become
+// its ExtendedData.
+func ToKML(fc *FeatureCollection, options KMLOptions) ([]byte, error) {
+	document := kmlDocument{Name: options.DocumentName}
+	if fc != nil {
+		for _, feature := range fc.Features {
+			if feature == nil {
+				continue
+			}
+			placemark := &kmlPlacemark{
+				ID:          feature.IDStr(),
+				Name:        feature.PropertyString(KMLNAME),
+				Description: feature.PropertyString(KMLDESCRIPTION)}
+			placemark.kmlMultiGeometry.add(feature.resolvedGeometry())
+			if options.Styles {
+				placemark.Style = kmlStyleFromProperties(feature)
+			}
+			var keys []string
+			for key := range feature.Properties {
+				if key != KMLNAME && key != KMLDESCRIPTION {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			if len(keys) > 0 {
+				placemark.ExtendedData = &kmlExtendedData{}
+				for _, key := range keys {
+					placemark.ExtendedData.Data = append(placemark.ExtendedData.Data,
+						kmlData{Name: key, Value: kmlValue(feature.Properties[key])})
+				}
+			}
+			document.Placemarks = append(document.Placemarks, placemark)
+		}
+	}
+	result, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), result...), nil
+}
+
+// add adds the geometry to the element,
+// with Multi geometries and GeometryCollections as MultiGeometries
+func (multi *kmlMultiGeometry) add(geometry interface{}) {
+	switch typed := geometry.(type) {
+	case *Point:
+		multi.Elements = append(multi.Elements, kmlGeometry{
+			XMLName: xml.Name{Local: "Point"}, Coordinates: formatKMLCoordinates([][]float64{typed.Coordinates})})
+	case *LineString:
+		multi.Elements = append(multi.Elements, kmlGeometry{
+			XMLName: xml.Name{Local: "LineString"}, Coordinates: formatKMLCoordinates(typed.Coordinates)})
+	case *Polygon:
+		multi.Elements = append(multi.Elements, newKMLPolygon(typed.Coordinates))
+	case *MultiPoint:
+		part := newKMLMultiGeometry()
+		for _, position := range typed.Coordinates {
+			part.add(NewPoint(position))
+		}
+		multi.Elements = append(multi.Elements, part)
+	case *MultiLineString:
+		part := newKMLMultiGeometry()
+		for _, line := range typed.Coordinates {
+			part.add(NewLineString(line))
+		}
+		multi.Elements = append(multi.Elements, part)
+	case *MultiPolygon:
+		part := newKMLMultiGeometry()
+		for _, polygon := range typed.Coordinates {
+			part.add(NewPolygon(polygon))
+		}
+		multi.Elements = append(multi.Elements, part)
+	case *GeometryCollection:
+		part := newKMLMultiGeometry()
+		for _, curr := range typed.Geometries {
+			part.add(curr)
+		}
+		multi.Elements = append(multi.Elements, part)
+	}
+}
+
+func newKMLMultiGeometry() kmlGeometry {
+	return kmlGeometry{XMLName: xml.Name{Local: "MultiGeometry"}}
+}
+
+func newKMLPolygon(rings [][][]float64) kmlGeometry {
+	result := kmlGeometry{XMLName: xml.Name{Local: "Polygon"}}
+	for inx, ring := range rings {
+		boundary := kmlBoundary{kmlCoordinates{formatKMLCoordinates(ring)}}
+		if inx == 0 {
+			result.Outer = &boundary
+		} else {
+			result.Inner = append(result.Inner, boundary)
+		}
+	}
+	return result
+}
+
+func formatKMLCoordinates(positions [][]float64) string {
+	tuples := make([]string, 0, len(positions))
+	for _, position := range positions {
+		if len(position) > 3 {
+			position = position[:3]
+		}
+		values := make([]string, len(position))
+		for inx, value := range position {
+			values[inx] = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		tuples = append(tuples, strings.Join(values, ","))
+	}
+	return strings.Join(tuples, " ")
+}
+
+// kmlValue returns the text of a property value
+func kmlValue(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string, fmt.Stringer, int, int64, float32, float64:
+		return stringify(typed)
+	}
+	if bytes, err := json.Marshal(value); err == nil {
+		return string(bytes)
+	}
+	return fmt.Sprint(value)
+}
+
+// kmlStyleFromProperties returns the Style described by the Feature's
+// simplestyle properties, or nil if it has none
+func kmlStyleFromProperties(feature *Feature) *kmlStyle {
+	var result kmlStyle
+	opacity := func(name string) float64 {
+		if value := feature.PropertyFloat(name); !math.IsNaN(value) {
+			return value
+		}
+		return 1
+	}
+	if color, ok := kmlColor(feature.PropertyString("marker-color"), 1); ok {
+		result.IconStyle = &kmlColorStyle{Color: color}
+	}
+	color, hasColor := kmlColor(feature.PropertyString("stroke"), opacity("stroke-opacity"))
+	width := feature.PropertyFloat("stroke-width")
+	if hasColor || !math.IsNaN(width) {
+		result.LineStyle = &kmlLineStyle{Color: color}
+		if !math.IsNaN(width) {
+			result.LineStyle.Width = width
+		}
+	}
+	if color, ok := kmlColor(feature.PropertyString("fill"), opacity("fill-opacity")); ok {
+		result.PolyStyle = &kmlColorStyle{Color: color}
+	}
+	if result.IconStyle == nil && result.LineStyle == nil && result.PolyStyle == nil {
+		return nil
+	}
+	return &result
+}
+
+// kmlColor converts a CSS color, #rrggbb or #rgb, and an opacity
+// into the aabbggrr form KML uses
+func kmlColor(css string, opacity float64) (string, bool) {
+	css = strings.TrimPrefix(strings.TrimSpace(css), "#")
+	if len(css) == 3 {
+		css = string([]byte{css[0], css[0], css[1], css[1], css[2], css[2]})
+	}
+	if len(css) != 6 {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(css, 16, 32); err != nil {
+		return "", false
+	}
+	alpha := int(math.Round(math.Max(0, math.Min(1, opacity)) * 255))
+	return strings.ToLower(fmt.Sprintf("%02x%v%v%v", alpha, css[4:6], css[2:4], css[0:2])), true
+}
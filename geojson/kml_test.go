@@ -0,0 +1,210 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"strings"
+	"testing"
+)
+
+const kmlSample = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>Sample</name>
+    <Placemark id="top">
+      <name> Top </name>
+      <Point><coordinates>1,2,3</coordinates></Point>
+    </Placemark>
+    <Folder>
+      <name>Outer</name>
+      <Folder>
+        <name>Inner</name>
+        <Placemark>
+          <name>Route</name>
+          <description><![CDATA[A <b>route</b>]]></description>
+          <ExtendedData>
+            <Data name="speed"><value>30</value></Data>
+            <SchemaData schemaUrl="#schema"><SimpleData name="lanes">2</SimpleData></SchemaData>
+          </ExtendedData>
+          <LineString><coordinates>
+            0,0 1, 1
+            2,0
+          </coordinates></LineString>
+        </Placemark>
+      </Folder>
+      <Placemark>
+        <Polygon>
+          <outerBoundaryIs><LinearRing><coordinates>0,0 10,0 10,10 0,10 0,0</coordinates></LinearRing></outerBoundaryIs>
+          <innerBoundaryIs><LinearRing><coordinates>2,2 2,4 4,4 4,2 2,2</coordinates></LinearRing></innerBoundaryIs>
+        </Polygon>
+      </Placemark>
+    </Folder>
+    <Placemark>
+      <MultiGeometry>
+        <Point><coordinates>1,1</coordinates></Point>
+        <Point><coordinates>2,2</coordinates></Point>
+      </MultiGeometry>
+    </Placemark>
+    <Placemark>
+      <MultiGeometry>
+        <Point><coordinates>1,1</coordinates></Point>
+        <LineString><coordinates>0,0 1,1</coordinates></LineString>
+      </MultiGeometry>
+    </Placemark>
+    <Placemark><name>Nowhere</name></Placemark>
+  </Document>
+</kml>`
+
+func TestFromKML(t *testing.T) {
+	fc, err := FromKML([]byte(kmlSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 6 {
+		t.Fatalf("Expected 6 features, got %v", fc.String())
+	}
+	expected := []string{
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{"name":"Top"},"id":"top"}`,
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1],[2,0]]},"properties":{"description":"A \u003cb\u003eroute\u003c/b\u003e","folder":"Outer/Inner","lanes":"2","name":"Route","speed":"30"}}`,
+		`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,4],[4,4],[4,2],[2,2]]]},"properties":{"folder":"Outer"}}`,
+		`{"type":"Feature","geometry":{"type":"MultiPoint","coordinates":[[1,1],[2,2]]},"properties":{}}`,
+		`{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,1]},{"type":"LineString","coordinates":[[0,0],[1,1]]}]},"properties":{}}`,
+		`{"type":"Feature","geometry":null,"properties":{"name":"Nowhere"}}`,
+	}
+	for inx, feature := range fc.Features {
+		if actual := feature.String(); actual != expected[inx] {
+			t.Errorf("Expected %v, got %v", expected[inx], actual)
+		}
+	}
+
+	// Several geometries outside a MultiGeometry are all kept
+	fc, err = FromKML([]byte(`<kml><Placemark><Point><coordinates>1,1</coordinates></Point>` +
+		`<LineString><coordinates>0,0 1,1</coordinates></LineString></Placemark></kml>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedGeometry := `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,1]},{"type":"LineString","coordinates":[[0,0],[1,1]]}]}`
+	if len(fc.Features) != 1 || stringify(fc.Features[0].Geometry) != expectedGeometry {
+		t.Errorf("Expected %v, got %v", expectedGeometry, fc.String())
+	}
+
+	// Folders without a name are left out of the folder path
+	fc, err = FromKML([]byte(`<kml><Folder><Folder><name>Named</name><Folder>` +
+		`<Placemark><Point><coordinates>1,1</coordinates></Point></Placemark></Folder></Folder>` +
+		`<Placemark><Point><coordinates>2,2</coordinates></Point></Placemark></Folder></kml>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 2 || fc.Features[0].PropertyString(KMLFOLDER) != "Named" {
+		t.Errorf("Expected the named folder alone, got %v", fc.String())
+	} else if _, ok := fc.Features[1].Properties[KMLFOLDER]; ok {
+		t.Errorf("Expected no folder for an unnamed folder, got %v", fc.Features[1].String())
+	}
+
+	// A Polygon without an outer boundary is empty
+	if fc, err = FromKML([]byte(`<kml><Placemark><Polygon></Polygon></Placemark></kml>`)); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"type":"Polygon","coordinates":[]}`; stringify(fc.Features[0].Geometry) != expected {
+		t.Errorf("Expected %v, got %v", expected, fc.String())
+	}
+
+	for _, input := range []string{
+		`<kml><Placemark><Polygon><innerBoundaryIs><LinearRing><coordinates>2,2 2,4 4,4 2,2</coordinates>` +
+			`</LinearRing></innerBoundaryIs></Polygon></Placemark></kml>`,
+		`<kml><Placemark><Polygon><outerBoundaryIs><LinearRing><coordinates></coordinates></LinearRing></outerBoundaryIs>` +
+			`<innerBoundaryIs><LinearRing><coordinates>2,2 2,4 4,4 2,2</coordinates></LinearRing></innerBoundaryIs></Polygon></Placemark></kml>`,
+		`<kml><Placemark><Point><coordinates>1</coordinates></Point></Placemark></kml>`,
+		`<kml><Placemark><Point><coordinates>a,b</coordinates></Point></Placemark></kml>`,
+		`<kml><Placemark>`,
+	} {
+		if _, err = FromKML([]byte(input)); err == nil {
+			t.Errorf("Expected an error for %v", input)
+		}
+	}
+}
+
+func TestToKML(t *testing.T) {
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{1.5, 2, 3}), "p", map[string]interface{}{
+			"name": "Point", "marker-color": "#f00", "count": 2.0, "tags": []interface{}{"a"}}),
+		NewFeature(NewMultiLineString([][][]float64{{{0, 0}, {1, 1}}, {{2, 2}, {3, 3}}}), nil, map[string]interface{}{
+			"stroke": "#00ff80", "stroke-opacity": 0.5, "stroke-width": 3.0}),
+		NewFeature(NewMultiPolygon([][][][]float64{
+			{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, {{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}},
+			{{{20, 20}, {21, 20}, {21, 21}, {20, 20}}}}), nil, map[string]interface{}{"fill": "#0000ff"}),
+		NewFeature(nil, nil, map[string]interface{}{"description": "No geometry"})})
+
+	data, err := ToKML(fc, KMLOptions{DocumentName: "Output", Styles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, expected := range []string{
+		`<kml xmlns="http://www.opengis.net/kml/2.2">`,
+		`<name>Output</name>`,
+		`<Placemark id="p">`,
+		`<IconStyle>`, `<color>ff0000ff</color>`,
+		`<color>8080ff00</color>`, `<width>3</width>`,
+		`<color>ffff0000</color>`,
+		`<Data name="tags">`, `<value>[&#34;a&#34;]</value>`,
+		`<coordinates>1.5,2,3</coordinates>`,
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %v in %v", expected, text)
+		}
+	}
+
+	result, err := FromKML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Features) != len(fc.Features) {
+		t.Fatalf("Expected %v features, got %v", len(fc.Features), result.String())
+	}
+	for inx, feature := range result.Features[:3] {
+		if !Equals(feature.Geometry, fc.Features[inx].Geometry) {
+			t.Errorf("Expected %v, got %v", fc.Features[inx].Geometry, feature.Geometry)
+		}
+	}
+	if feature := result.Features[0]; feature.ID != "p" || feature.PropertyString("name") != "Point" || feature.PropertyString("count") != "2" {
+		t.Errorf("Expected the ID and properties to be kept, got %v", feature.String())
+	}
+	if feature := result.Features[3]; feature.Geometry != nil || feature.PropertyString("description") != "No geometry" {
+		t.Errorf("Expected the description without a geometry, got %v", feature.String())
+	}
+
+	if data, err = ToKML(fc, KMLOptions{}); err != nil || strings.Contains(string(data), "<Style>") {
+		t.Errorf("Expected no styles, got %v, %v", string(data), err)
+	}
+
+	// The parts of a GeometryCollection keep their order
+	gc := NewGeometryCollection([]interface{}{
+		NewLineString([][]float64{{0, 0}, {1, 1}}),
+		NewPoint([]float64{1, 2}),
+		NewMultiPoint([][]float64{{3, 4}, {5, 6}}),
+		NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})})
+	if data, err = ToKML(NewFeatureCollection([]*Feature{NewFeature(gc, nil, nil)}), KMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if result, err = FromKML(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Features) != 1 || !Equals(result.Features[0].Geometry, gc) {
+		t.Errorf("Expected %v, got %v", gc.String(), result.String())
+	}
+}
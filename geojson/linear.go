@@ -0,0 +1,253 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "math"
+
+// The linear referencing functions in this file accept a *LineString or a
+// *MultiLineString. The parts of a MultiLineString are measured one after
+// another as if they were joined; the gaps between them have no length.
+// Distances are in coordinate units in Planar mode and metres in Geodesic mode.
+
+// LineLength returns the length of a LineString or MultiLineString
+func LineLength(line interface{}, mode DistanceMode) float64 {
+	var result float64
+	for _, part := range lineParts(line) {
+		for inx := 1; inx < len(part); inx++ {
+			result += positionDistance(part[inx-1], part[inx], mode)
+		}
+	}
+	return result
+}
+
+// Interpolate returns the point the given distance along the line.
+// Distances beyond either end are clamped to that end.
+// It returns nil if the line has no positions.
+func Interpolate(line interface{}, distance float64, mode DistanceMode) *Point {
+	parts := lineParts(line)
+	var (
+		travelled float64
+		last      []float64
+	)
+	for _, part := range parts {
+		for inx := 1; inx < len(part); inx++ {
+			a, b := part[inx-1], part[inx]
+			length := positionDistance(a, b, mode)
+			if travelled+length >= distance && length > 0 {
+				fraction := math.Max(0, (distance-travelled)/length)
+				return NewPoint(interpolate(a, b, fraction, mode))
+			}
+			travelled += length
+		}
+		if len(part) > 0 {
+			if last == nil && distance <= 0 {
+				return NewPoint(clone1(part[0]))
+			}
+			last = part[len(part)-1]
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	return NewPoint(clone1(last))
+}
+
+// InterpolateFraction returns the point the given fraction (0 to 1)
+// of the way along the line
+func InterpolateFraction(line interface{}, fraction float64, mode DistanceMode) *Point {
+	return Interpolate(line, fraction*LineLength(line, mode), mode)
+}
+
+// LocatePoint returns the distance along the line to the position
+// on the line nearest the point provided
+func LocatePoint(line interface{}, point *Point, mode DistanceMode) float64 {
+	_, along, _ := nearestOnLine(line, point, mode)
+	return along
+}
+
+// NearestPointOnLine returns the point on the line nearest the point provided
+// and the distance between them, or nil if either has no positions
+func NearestPointOnLine(line interface{}, point *Point, mode DistanceMode) (*Point, float64) {
+	nearest, _, distance := nearestOnLine(line, point, mode)
+	if nearest == nil {
+		return nil, math.NaN()
+	}
+	return NewPoint(nearest), distance
+}
+
+// SubstringLine returns the part of the line between two distances along it.
+// If start is greater than end the result runs in the opposite direction.
+// The result is a *LineString, or a *MultiLineString if it spans
+// more than one part of a MultiLineString.
+func SubstringLine(line interface{}, start, end float64, mode DistanceMode) interface{} {
+	reverse := start > end
+	if reverse {
+		start, end = end, start
+	}
+	var (
+		pieces    [][][]float64
+		travelled float64
+	)
+	for _, part := range lineParts(line) {
+		var piece [][]float64
+		for inx := 1; inx < len(part); inx++ {
+			a, b := part[inx-1], part[inx]
+			length := positionDistance(a, b, mode)
+			segmentStart, segmentEnd := travelled, travelled+length
+			travelled = segmentEnd
+			if segmentEnd < start || segmentStart > end || length == 0 {
+				continue
+			}
+			from := interpolate(a, b, math.Max(0, (start-segmentStart)/length), mode)
+			to := interpolate(a, b, math.Min(1, (end-segmentStart)/length), mode)
+			if len(piece) == 0 || !samePosition(piece[len(piece)-1], from) {
+				piece = append(piece, from)
+			}
+			if !samePosition(piece[len(piece)-1], to) {
+				piece = append(piece, to)
+			}
+		}
+		if len(piece) == 1 {
+			// The substring has no length, so repeat the position to keep a valid line
+			piece = append(piece, clone1(piece[0]))
+		}
+		if len(piece) > 0 {
+			pieces = append(pieces, piece)
+		}
+	}
+	if reverse {
+		for i, j := 0, len(pieces)-1; i < j; i, j = i+1, j-1 {
+			pieces[i], pieces[j] = pieces[j], pieces[i]
+		}
+		for _, piece := range pieces {
+			reverseSequence(piece)
+		}
+	}
+	switch len(pieces) {
+	case 0:
+		return NewLineString([][]float64{})
+	case 1:
+		return NewLineString(pieces[0])
+	}
+	return NewMultiLineString(pieces)
+}
+
+func lineParts(line interface{}) [][][]float64 {
+	switch typed := line.(type) {
+	case *LineString:
+		return [][][]float64{typed.Coordinates}
+	case *MultiLineString:
+		return typed.Coordinates
+	}
+	return nil
+}
+
+// nearestOnLine returns the position on the line nearest the point,
+// the distance along the line to it, and the distance from the point to it
+func nearestOnLine(line interface{}, point *Point, mode DistanceMode) ([]float64, float64, float64) {
+	var (
+		result    []float64
+		along     float64
+		best      = math.Inf(1)
+		travelled float64
+	)
+	if point == nil || len(point.Coordinates) < 2 {
+		return nil, math.NaN(), math.NaN()
+	}
+	for _, part := range lineParts(line) {
+		if len(part) == 1 {
+			if distance := positionDistance(point.Coordinates, part[0], mode); distance < best {
+				result, along, best = clone1(part[0]), travelled, distance
+			}
+		}
+		for inx := 1; inx < len(part); inx++ {
+			a, b := part[inx-1], part[inx]
+			nearest, fraction, distance := nearestOnSegment(point.Coordinates, a, b, mode)
+			length := positionDistance(a, b, mode)
+			if distance < best {
+				result, along, best = nearest, travelled+fraction*length, distance
+			}
+			travelled += length
+		}
+	}
+	if result == nil {
+		return nil, math.NaN(), math.NaN()
+	}
+	return result, along, best
+}
+
+// nearestOnSegment returns the position on the segment a-b nearest p,
+// the fraction of the way along the segment at which it lies,
+// and its distance from p
+func nearestOnSegment(p, a, b []float64, mode DistanceMode) ([]float64, float64, float64) {
+	var fraction float64
+	if mode == Geodesic {
+		fraction = greatCircleFraction(p, a, b)
+	} else {
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+			fraction = ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / lengthSquared
+		}
+	}
+	fraction = math.Max(0, math.Min(1, fraction))
+	result := interpolate(a, b, fraction, mode)
+	return result, fraction, positionDistance(p, result, mode)
+}
+
+// greatCircleFraction returns the fraction of the way along the great circle
+// from a to b of the position on it nearest p, which may lie outside 0 to 1
+func greatCircleFraction(p, a, b []float64) float64 {
+	va, vb, vp := unitVector(a), unitVector(b), unitVector(p)
+	normal := crossVectors(va, vb)
+	if norm := vectorLength(normal); norm == 0 {
+		return 0
+	}
+	// Project p onto the plane of the great circle
+	dot := dotVectors(vp, normal) / dotVectors(normal, normal)
+	projected := [3]float64{vp[0] - dot*normal[0], vp[1] - dot*normal[1], vp[2] - dot*normal[2]}
+	if vectorLength(projected) == 0 {
+		return 0
+	}
+	total := vectorAngle(va, vb)
+	angle := vectorAngle(va, projected)
+	// Negative if the projection lies behind a
+	if dotVectors(crossVectors(va, projected), normal) < 0 {
+		angle = -angle
+	}
+	return angle / total
+}
+
+func unitVector(position []float64) [3]float64 {
+	lat, lon := toRadians(position[1]), toRadians(position[0])
+	return [3]float64{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+func crossVectors(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func dotVectors(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func vectorLength(a [3]float64) float64 {
+	return math.Sqrt(dotVectors(a, a))
+}
+
+func vectorAngle(a, b [3]float64) float64 {
+	return math.Atan2(vectorLength(crossVectors(a, b)), dotVectors(a, b))
+}
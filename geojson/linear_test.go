@@ -0,0 +1,104 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLinearReferencingPlanar(t *testing.T) {
+	ls := NewLineString([][]float64{{0, 0}, {10, 0}, {10, 10}})
+	testClose(t, "length", 20, LineLength(ls, Planar), 1e-12)
+
+	point := Interpolate(ls, 15, Planar)
+	if point.String() != `{"type":"Point","coordinates":[10,5]}` {
+		t.Errorf("Unexpected interpolated point %v", point.String())
+	}
+	point = InterpolateFraction(ls, 0.25, Planar)
+	if point.String() != `{"type":"Point","coordinates":[5,0]}` {
+		t.Errorf("Unexpected interpolated point %v", point.String())
+	}
+	if point = Interpolate(ls, 100, Planar); point.String() != `{"type":"Point","coordinates":[10,10]}` {
+		t.Errorf("Expected the end of the line, got %v", point.String())
+	}
+	if point = Interpolate(ls, -1, Planar); point.String() != `{"type":"Point","coordinates":[0,0]}` {
+		t.Errorf("Expected the start of the line, got %v", point.String())
+	}
+
+	testClose(t, "locate", 13, LocatePoint(ls, NewPoint([]float64{12, 3}), Planar), 1e-12)
+	nearest, distance := NearestPointOnLine(ls, NewPoint([]float64{12, 3}), Planar)
+	if nearest.String() != `{"type":"Point","coordinates":[10,3]}` {
+		t.Errorf("Unexpected nearest point %v", nearest.String())
+	}
+	testClose(t, "nearest distance", 2, distance, 1e-12)
+
+	substring := SubstringLine(ls, 5, 15, Planar).(*LineString)
+	expected := `{"type":"LineString","coordinates":[[5,0],[10,0],[10,5]]}`
+	if substring.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, substring.String())
+	}
+	substring = SubstringLine(ls, 15, 5, Planar).(*LineString)
+	expected = `{"type":"LineString","coordinates":[[10,5],[10,0],[5,0]]}`
+	if substring.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, substring.String())
+	}
+}
+
+func TestLinearReferencingMultiLineString(t *testing.T) {
+	mls := NewMultiLineString([][][]float64{{{0, 0}, {10, 0}}, {{20, 0}, {20, 10}}})
+	testClose(t, "length", 20, LineLength(mls, Planar), 1e-12)
+	if point := Interpolate(mls, 12, Planar); point.String() != `{"type":"Point","coordinates":[20,2]}` {
+		t.Errorf("Unexpected interpolated point %v", point.String())
+	}
+	testClose(t, "locate", 14, LocatePoint(mls, NewPoint([]float64{21, 4}), Planar), 1e-12)
+
+	substring := SubstringLine(mls, 5, 15, Planar).(*MultiLineString)
+	expected := `{"type":"MultiLineString","coordinates":[[[5,0],[10,0]],[[20,0],[20,5]]]}`
+	if substring.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, substring.String())
+	}
+	if Interpolate(NewLineString([][]float64{}), 1, Planar) != nil {
+		t.Error("Expected nil for an empty line")
+	}
+}
+
+func TestLinearReferencingGeodesic(t *testing.T) {
+	// A quarter of the equator
+	ls := NewLineString([][]float64{{0, 0}, {90, 0}})
+	quarter := math.Pi / 2 * EarthRadius
+	testClose(t, "length", quarter, LineLength(ls, Geodesic), 1e-6)
+
+	point := InterpolateFraction(ls, 0.5, Geodesic)
+	testClose(t, "interpolated longitude", 45, point.Coordinates[0], 1e-9)
+	testClose(t, "interpolated latitude", 0, point.Coordinates[1], 1e-9)
+
+	// The nearest point on the equator is due south
+	nearest, distance := NearestPointOnLine(ls, NewPoint([]float64{30, 10}), Geodesic)
+	testClose(t, "nearest longitude", 30, nearest.Coordinates[0], 1e-9)
+	testClose(t, "nearest latitude", 0, nearest.Coordinates[1], 1e-9)
+	testClose(t, "nearest distance", 10*math.Pi/180*EarthRadius, distance, 1e-6)
+	testClose(t, "locate", quarter/3, LocatePoint(ls, NewPoint([]float64{30, 10}), Geodesic), 1e-6)
+
+	// Beyond the end of the line
+	nearest, _ = NearestPointOnLine(ls, NewPoint([]float64{120, 5}), Geodesic)
+	testClose(t, "clamped longitude", 90, nearest.Coordinates[0], 1e-9)
+
+	substring := SubstringLine(ls, quarter/3, 2*quarter/3, Geodesic).(*LineString)
+	testClose(t, "substring start", 30, substring.Coordinates[0][0], 1e-9)
+	testClose(t, "substring end", 60, substring.Coordinates[1][0], 1e-9)
+}
@@ -0,0 +1,645 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultMVTExtent is the number of units across a vector tile
+// used when no extent is given
+const DefaultMVTExtent = 4096
+
+// MVT geometry types
+const (
+	mvtPoint      = 1
+	mvtLineString = 2
+	mvtPolygon    = 3
+)
+
+// MVT geometry commands
+const (
+	mvtMoveTo    = 1
+	mvtLineTo    = 2
+	mvtClosePath = 7
+)
+
+// EncodeMVT returns a Mapbox Vector Tile containing a single layer with
+// the features of the collection that fall within the tile.
+// Geometries are projected into tile space, clipped to the tile with a buffer
+// of 1/64 of its extent, and rounded to whole units. Properties are encoded
+// as strings, numbers and booleans; other values are encoded as JSON strings
+// and nulls are omitted. Feature IDs are kept if they are non-negative integers.
+// If the extent is not positive, DefaultMVTExtent is used.
+func EncodeMVT(fc *FeatureCollection, layerName string, tile Tile, extent int) ([]byte, error) {
+	if fc == nil {
+		return nil, errors.New("EncodeMVT requires a FeatureCollection")
+	}
+	if extent <= 0 {
+		extent = DefaultMVTExtent
+	}
+	buffer := float64(extent) / 64
+	clip := rtreeRect{-buffer, -buffer, float64(extent) + buffer, float64(extent) + buffer}
+	n := math.Exp2(float64(tile.Z))
+	toTile := func(position []float64) []float64 {
+		x, y := mercatorFraction(position[0], position[1])
+		return []float64{(x*n - float64(tile.X)) * float64(extent), (y*n - float64(tile.Y)) * float64(extent)}
+	}
+
+	var features []*Feature
+	for _, feature := range fc.Features {
+		if feature == nil || feature.Geometry == nil {
+			continue
+		}
+		projected, err := MapCoordinates(feature.resolvedGeometry(), toTile)
+		if err != nil {
+			return nil, err
+		}
+		if clipped := clipGeometry(projected, clip); clipped != nil {
+			features = append(features, &Feature{Type: FEATURE, Geometry: clipped, ID: feature.ID, Properties: feature.Properties})
+		}
+	}
+	return encodeMVTTile([][]byte{encodeMVTLayer(layerName, extent, features)}), nil
+}
+
+// DecodeMVT returns the layers of a Mapbox Vector Tile as FeatureCollections
+// in longitude/latitude, keyed by layer name.
+// Feature IDs and numeric properties are decoded as float64, as encoding/json would.
+func DecodeMVT(data []byte, tile Tile) (map[string]*FeatureCollection, error) {
+	result := make(map[string]*FeatureCollection)
+	reader := protoReader{data: data}
+	for !reader.done() {
+		field, wireType, err := reader.key()
+		if err != nil {
+			return nil, err
+		}
+		if field != 3 || wireType != protoBytes {
+			if err = reader.skip(wireType); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		layerData, err := reader.bytes()
+		if err != nil {
+			return nil, err
+		}
+		name, fc, err := decodeMVTLayer(layerData, tile)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = fc
+	}
+	return result, nil
+}
+
+// mercatorFraction returns the position of a longitude/latitude on a
+// Web Mercator world map running from 0 to 1, with y increasing southward
+func mercatorFraction(longitude, latitude float64) (float64, float64) {
+	latitude = math.Max(-MaxMercatorLatitude, math.Min(MaxMercatorLatitude, latitude))
+	sin := math.Sin(toRadians(latitude))
+	return longitude/360 + 0.5, 0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)
+}
+
+// mercatorLonLat is the inverse of mercatorFraction
+func mercatorLonLat(x, y float64) (float64, float64) {
+	return (x - 0.5) * 360, toDegrees(math.Atan(math.Sinh(math.Pi * (1 - 2*y))))
+}
+
+func encodeMVTTile(layers [][]byte) []byte {
+	var result []byte
+	for _, layer := range layers {
+		result = appendProtoBytes(result, 3, layer)
+	}
+	return result
+}
+
+// encodeMVTLayer returns an MVT layer of features whose geometries
+// are already in tile coordinates
+func encodeMVTLayer(name string, extent int, features []*Feature) []byte {
+	var (
+		keys            []string
+		keyIndex        = make(map[string]int)
+		values          [][]byte
+		valueIndex      = make(map[string]int)
+		encodedFeatures [][]byte
+	)
+	for _, feature := range features {
+		var tags []uint64
+		names := make([]string, 0, len(feature.Properties))
+		for key := range feature.Properties {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		for _, key := range names {
+			value, ok := encodeMVTValue(feature.Properties[key])
+			if !ok {
+				continue
+			}
+			if _, found := keyIndex[key]; !found {
+				keyIndex[key] = len(keys)
+				keys = append(keys, key)
+			}
+			if _, found := valueIndex[string(value)]; !found {
+				valueIndex[string(value)] = len(values)
+				values = append(values, value)
+			}
+			tags = append(tags, uint64(keyIndex[key]), uint64(valueIndex[string(value)]))
+		}
+		id, hasID := mvtFeatureID(feature.ID)
+		for _, encoded := range encodeMVTGeometries(feature.Geometry) {
+			var message []byte
+			if hasID {
+				message = appendProtoVarint(message, 1, id)
+			}
+			if len(tags) > 0 {
+				message = appendProtoPacked(message, 2, tags)
+			}
+			message = appendProtoVarint(message, 3, uint64(encoded.geometryType))
+			message = appendProtoPacked(message, 4, encoded.commands)
+			encodedFeatures = append(encodedFeatures, message)
+		}
+	}
+
+	var result []byte
+	result = appendProtoVarint(result, 15, 2)
+	result = appendProtoBytes(result, 1, []byte(name))
+	for _, feature := range encodedFeatures {
+		result = appendProtoBytes(result, 2, feature)
+	}
+	for _, key := range keys {
+		result = appendProtoBytes(result, 3, []byte(key))
+	}
+	for _, value := range values {
+		result = appendProtoBytes(result, 4, value)
+	}
+	return appendProtoVarint(result, 5, uint64(extent))
+}
+
+func mvtFeatureID(id interface{}) (uint64, bool) {
+	switch typed := id.(type) {
+	case int:
+		return uint64(typed), typed >= 0
+	case int64:
+		return uint64(typed), typed >= 0
+	case uint64:
+		return typed, true
+	case float64:
+		return uint64(typed), typed >= 0 && typed == math.Trunc(typed) && typed < math.MaxUint64
+	}
+	return 0, false
+}
+
+// encodeMVTValue returns an encoded MVT Value message, or false for nulls
+func encodeMVTValue(value interface{}) ([]byte, bool) {
+	var result []byte
+	switch typed := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		result = appendProtoBytes(result, 1, []byte(typed))
+	case bool:
+		flag := uint64(0)
+		if typed {
+			flag = 1
+		}
+		result = appendProtoVarint(result, 7, flag)
+	case float32:
+		var bits [4]byte
+		binary.LittleEndian.PutUint32(bits[:], math.Float32bits(typed))
+		result = append(appendProtoKey(result, 2, protoFixed32), bits[:]...)
+	case float64:
+		if typed == math.Trunc(typed) && math.Abs(typed) < 1<<53 {
+			return encodeMVTValue(int64(typed))
+		}
+		var bits [8]byte
+		binary.LittleEndian.PutUint64(bits[:], math.Float64bits(typed))
+		result = append(appendProtoKey(result, 3, protoFixed64), bits[:]...)
+	case int:
+		return encodeMVTValue(int64(typed))
+	case int32:
+		return encodeMVTValue(int64(typed))
+	case int64:
+		if typed < 0 {
+			result = appendProtoVarint(result, 6, zigzag(typed))
+		} else {
+			result = appendProtoVarint(result, 5, uint64(typed))
+		}
+	case uint:
+		result = appendProtoVarint(result, 5, uint64(typed))
+	case uint32:
+		result = appendProtoVarint(result, 5, uint64(typed))
+	case uint64:
+		result = appendProtoVarint(result, 5, typed)
+	default:
+		bytes, err := json.Marshal(typed)
+		if err != nil {
+			return nil, false
+		}
+		result = appendProtoBytes(result, 1, bytes)
+	}
+	return result, true
+}
+
+type mvtGeometry struct {
+	geometryType int
+	commands     []uint64
+}
+
+// encodeMVTGeometries returns the MVT geometries of a geometry in tile
+// coordinates; GeometryCollections become one geometry per member
+func encodeMVTGeometries(geometry interface{}) []mvtGeometry {
+	var (
+		result  []mvtGeometry
+		encoder mvtEncoder
+	)
+	switch typed := geometry.(type) {
+	case *Point:
+		encoder.points([][]float64{typed.Coordinates})
+		result = append(result, mvtGeometry{mvtPoint, encoder.commands})
+	case *MultiPoint:
+		encoder.points(typed.Coordinates)
+		result = append(result, mvtGeometry{mvtPoint, encoder.commands})
+	case *LineString:
+		encoder.line(typed.Coordinates)
+		result = append(result, mvtGeometry{mvtLineString, encoder.commands})
+	case *MultiLineString:
+		for _, line := range typed.Coordinates {
+			encoder.line(line)
+		}
+		result = append(result, mvtGeometry{mvtLineString, encoder.commands})
+	case *Polygon:
+		encoder.polygon(typed.Coordinates)
+		result = append(result, mvtGeometry{mvtPolygon, encoder.commands})
+	case *MultiPolygon:
+		for _, polygon := range typed.Coordinates {
+			encoder.polygon(polygon)
+		}
+		result = append(result, mvtGeometry{mvtPolygon, encoder.commands})
+	case *GeometryCollection:
+		for _, curr := range typed.Geometries {
+			result = append(result, encodeMVTGeometries(curr)...)
+		}
+		return result
+	}
+	// Drop geometries with nothing left after rounding
+	if len(result) == 1 && len(result[0].commands) == 0 {
+		return nil
+	}
+	return result
+}
+
+// mvtEncoder accumulates geometry commands relative to a cursor
+type mvtEncoder struct {
+	x, y     int64
+	commands []uint64
+}
+
+func mvtCommand(id, count int) uint64 {
+	return uint64(id&7) | uint64(count)<<3
+}
+
+func (encoder *mvtEncoder) moveTo(positions [][]int64, command int) {
+	encoder.commands = append(encoder.commands, mvtCommand(command, len(positions)))
+	for _, position := range positions {
+		encoder.commands = append(encoder.commands, zigzag(position[0]-encoder.x), zigzag(position[1]-encoder.y))
+		encoder.x, encoder.y = position[0], position[1]
+	}
+}
+
+func (encoder *mvtEncoder) points(positions [][]float64) {
+	rounded := roundTilePositions(positions, false)
+	if len(rounded) > 0 {
+		encoder.moveTo(rounded, mvtMoveTo)
+	}
+}
+
+func (encoder *mvtEncoder) line(line [][]float64) {
+	rounded := roundTilePositions(line, true)
+	if len(rounded) < 2 {
+		return
+	}
+	encoder.moveTo(rounded[:1], mvtMoveTo)
+	encoder.moveTo(rounded[1:], mvtLineTo)
+}
+
+// polygon encodes the rings of a polygon with the exterior ring having positive
+// area in tile coordinates and holes negative, as the MVT specification requires
+func (encoder *mvtEncoder) polygon(polygon [][][]float64) {
+	for inx, ring := range polygon {
+		rounded := roundTilePositions(ring, true)
+		if len(rounded) > 1 && rounded[0][0] == rounded[len(rounded)-1][0] && rounded[0][1] == rounded[len(rounded)-1][1] {
+			rounded = rounded[:len(rounded)-1]
+		}
+		var area int64
+		for i := range rounded {
+			j := (i + 1) % len(rounded)
+			area += rounded[i][0]*rounded[j][1] - rounded[j][0]*rounded[i][1]
+		}
+		if len(rounded) < 3 || area == 0 {
+			if inx == 0 {
+				return
+			}
+			continue
+		}
+		if (inx == 0) != (area > 0) {
+			// Reverse the ring but keep its starting position
+			for i, j := 1, len(rounded)-1; i < j; i, j = i+1, j-1 {
+				rounded[i], rounded[j] = rounded[j], rounded[i]
+			}
+		}
+		encoder.moveTo(rounded[:1], mvtMoveTo)
+		encoder.moveTo(rounded[1:], mvtLineTo)
+		encoder.commands = append(encoder.commands, mvtCommand(mvtClosePath, 1))
+	}
+}
+
+// roundTilePositions rounds positions to whole tile units,
+// optionally dropping consecutive repeats
+func roundTilePositions(positions [][]float64, dropRepeats bool) [][]int64 {
+	var result [][]int64
+	for _, position := range positions {
+		if len(position) < 2 {
+			continue
+		}
+		rounded := []int64{int64(math.Round(position[0])), int64(math.Round(position[1]))}
+		if dropRepeats && len(result) > 0 {
+			last := result[len(result)-1]
+			if last[0] == rounded[0] && last[1] == rounded[1] {
+				continue
+			}
+		}
+		result = append(result, rounded)
+	}
+	return result
+}
+
+func zigzag(value int64) uint64 {
+	return uint64((value << 1) ^ (value >> 63))
+}
+
+func unzigzag(value uint64) int64 {
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+// decodeMVTLayer returns the name of an MVT layer and its features in longitude/latitude
+func decodeMVTLayer(data []byte, tile Tile) (string, *FeatureCollection, error) {
+	var (
+		name     string
+		extent   uint64 = DefaultMVTExtent
+		keys     []string
+		values   []interface{}
+		features [][]byte
+	)
+	reader := protoReader{data: data}
+	for !reader.done() {
+		field, wireType, err := reader.key()
+		if err != nil {
+			return "", nil, err
+		}
+		switch {
+		case field == 1 && wireType == protoBytes:
+			var bytes []byte
+			bytes, err = reader.bytes()
+			name = string(bytes)
+		case field == 2 && wireType == protoBytes:
+			var bytes []byte
+			bytes, err = reader.bytes()
+			features = append(features, bytes)
+		case field == 3 && wireType == protoBytes:
+			var bytes []byte
+			bytes, err = reader.bytes()
+			keys = append(keys, string(bytes))
+		case field == 4 && wireType == protoBytes:
+			var bytes []byte
+			var value interface{}
+			if bytes, err = reader.bytes(); err == nil {
+				value, err = decodeMVTValue(bytes)
+				values = append(values, value)
+			}
+		case field == 5 && wireType == protoVarint:
+			extent, err = reader.varint()
+		default:
+			err = reader.skip(wireType)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if extent == 0 {
+		return "", nil, fmt.Errorf("Layer %v has an extent of zero", name)
+	}
+
+	n := math.Exp2(float64(tile.Z))
+	toLonLat := func(x, y int64) []float64 {
+		longitude, latitude := mercatorLonLat(
+			(float64(x)/float64(extent)+float64(tile.X))/n,
+			(float64(y)/float64(extent)+float64(tile.Y))/n)
+		return []float64{longitude, latitude}
+	}
+	result := NewFeatureCollection([]*Feature{})
+	for _, data := range features {
+		feature, err := decodeMVTFeature(data, keys, values, toLonLat)
+		if err != nil {
+			return "", nil, fmt.Errorf("Layer %v: %v", name, err)
+		}
+		if feature != nil {
+			result.Features = append(result.Features, feature)
+		}
+	}
+	return name, result, nil
+}
+
+func decodeMVTValue(data []byte) (interface{}, error) {
+	var result interface{}
+	reader := protoReader{data: data}
+	for !reader.done() {
+		field, wireType, err := reader.key()
+		if err != nil {
+			return nil, err
+		}
+		switch field {
+		case 1:
+			var bytes []byte
+			bytes, err = reader.bytes()
+			result = string(bytes)
+		case 2:
+			var bits uint32
+			bits, err = reader.fixed32()
+			result = float64(math.Float32frombits(bits))
+		case 3:
+			var bits uint64
+			bits, err = reader.fixed64()
+			result = math.Float64frombits(bits)
+		case 4:
+			var value uint64
+			value, err = reader.varint()
+			result = float64(int64(value))
+		case 5:
+			var value uint64
+			value, err = reader.varint()
+			result = float64(value)
+		case 6:
+			var value uint64
+			value, err = reader.varint()
+			result = float64(unzigzag(value))
+		case 7:
+			var value uint64
+			value, err = reader.varint()
+			result = value != 0
+		default:
+			err = reader.skip(wireType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// decodeMVTFeature returns a Feature decoded from an MVT feature message,
+// or nil if it has no geometry. The ID and numbers in properties are returned
+// as float64, as encoding/json would.
+func decodeMVTFeature(data []byte, keys []string, values []interface{}, toLonLat func(x, y int64) []float64) (*Feature, error) {
+	var (
+		id           interface{}
+		tags         []uint64
+		geometryType uint64
+		commands     []uint64
+	)
+	reader := protoReader{data: data}
+	for !reader.done() {
+		field, wireType, err := reader.key()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == 1 && wireType == protoVarint:
+			var value uint64
+			value, err = reader.varint()
+			id = float64(value)
+		case field == 2:
+			tags, err = reader.uint64s(wireType, tags)
+		case field == 3 && wireType == protoVarint:
+			geometryType, err = reader.varint()
+		case field == 4:
+			commands, err = reader.uint64s(wireType, commands)
+		default:
+			err = reader.skip(wireType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	properties := make(map[string]interface{})
+	for inx := 0; inx+1 < len(tags); inx += 2 {
+		if tags[inx] >= uint64(len(keys)) || tags[inx+1] >= uint64(len(values)) {
+			return nil, fmt.Errorf("Feature tag %v/%v is out of range", tags[inx], tags[inx+1])
+		}
+		properties[keys[tags[inx]]] = values[tags[inx+1]]
+	}
+	geometry, err := decodeMVTGeometry(int(geometryType), commands, toLonLat)
+	if err != nil || geometry == nil {
+		return nil, err
+	}
+	return NewFeature(geometry, id, properties), nil
+}
+
+// decodeMVTGeometry interprets geometry commands
+func decodeMVTGeometry(geometryType int, commands []uint64, toLonLat func(x, y int64) []float64) (interface{}, error) {
+	var (
+		x, y        int64
+		lines       [][][]float64
+		tileLines   [][][]int64
+		current     [][]float64
+		tileCurrent [][]int64
+	)
+	finish := func() {
+		if len(current) > 0 {
+			lines = append(lines, current)
+			tileLines = append(tileLines, tileCurrent)
+		}
+		current, tileCurrent = nil, nil
+	}
+	for inx := 0; inx < len(commands); {
+		command, count := int(commands[inx]&7), int(commands[inx]>>3)
+		inx++
+		switch command {
+		case mvtMoveTo, mvtLineTo:
+			if inx+2*count > len(commands) {
+				return nil, errors.New("Geometry commands are truncated")
+			}
+			for i := 0; i < count; i++ {
+				if command == mvtMoveTo && geometryType != mvtPoint {
+					finish()
+				}
+				x += unzigzag(commands[inx])
+				y += unzigzag(commands[inx+1])
+				inx += 2
+				current = append(current, toLonLat(x, y))
+				tileCurrent = append(tileCurrent, []int64{x, y})
+			}
+		case mvtClosePath:
+			if len(current) > 0 {
+				current = append(current, clone1(current[0]))
+			}
+		default:
+			return nil, fmt.Errorf("Unknown geometry command %v", command)
+		}
+	}
+	finish()
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	switch geometryType {
+	case mvtPoint:
+		if len(lines[0]) == 1 {
+			return NewPoint(lines[0][0]), nil
+		}
+		return NewMultiPoint(lines[0]), nil
+	case mvtLineString:
+		return linesGeometry(lines), nil
+	case mvtPolygon:
+		// Rings with positive area in tile coordinates start new polygons.
+		// Flipping the y axis reverses every ring, so reverse them again
+		// to follow the RFC 7946 right-hand rule.
+		var polygons [][][][]float64
+		for inx, ring := range lines {
+			var area int64
+			tileRing := tileLines[inx]
+			for i := range tileRing {
+				j := (i + 1) % len(tileRing)
+				area += tileRing[i][0]*tileRing[j][1] - tileRing[j][0]*tileRing[i][1]
+			}
+			reverseSequence(ring)
+			if area > 0 || len(polygons) == 0 {
+				polygons = append(polygons, [][][]float64{ring})
+			} else {
+				last := len(polygons) - 1
+				polygons[last] = append(polygons[last], ring)
+			}
+		}
+		return polygonsGeometry(polygons), nil
+	}
+	return nil, fmt.Errorf("Unknown geometry type %v", geometryType)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMVTGeometryEncoding(t *testing.T) {
+	// The examples from section 4.3.5 of the MVT specification
+	testCases := []struct {
+		geometry interface{}
+		expected string
+	}{
+		{NewPoint([]float64{25, 17}), "[9 50 34]"},
+		{NewMultiPoint([][]float64{{5, 7}, {3, 2}}), "[17 10 14 3 9]"},
+		{NewLineString([][]float64{{2, 2}, {2, 10}, {10, 10}}), "[9 4 4 18 0 16 16 0]"},
+		{NewMultiLineString([][][]float64{{{2, 2}, {2, 10}, {10, 10}}, {{1, 1}, {3, 5}}}), "[9 4 4 18 0 16 16 0 9 17 17 10 4 8]"},
+		{NewPolygon([][][]float64{{{3, 6}, {8, 12}, {20, 34}, {3, 6}}}), "[9 6 12 18 10 12 24 44 15]"},
+		{NewMultiPolygon([][][][]float64{
+			{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+			{{{11, 11}, {20, 11}, {20, 20}, {11, 20}, {11, 11}}, {{13, 13}, {13, 17}, {17, 17}, {17, 13}, {13, 13}}}}),
+			"[9 0 0 26 20 0 0 20 19 0 15 9 22 2 26 18 0 0 18 17 0 15 9 4 13 26 0 8 8 0 0 7 15]"},
+	}
+	for _, testCase := range testCases {
+		encoded := encodeMVTGeometries(testCase.geometry)
+		if len(encoded) != 1 {
+			t.Errorf("Expected one geometry, got %v", encoded)
+			continue
+		}
+		if actual := fmt.Sprint(encoded[0].commands); actual != testCase.expected {
+			t.Errorf("Expected %v, got %v", testCase.expected, actual)
+		}
+	}
+
+	// Clockwise exterior rings in tile coordinates are reversed
+	encoded := encodeMVTGeometries(NewPolygon([][][]float64{{{3, 6}, {20, 34}, {8, 12}, {3, 6}}}))
+	if actual := fmt.Sprint(encoded[0].commands); actual != "[9 6 12 18 10 12 24 44 15]" {
+		t.Errorf("Expected the ring to be reversed, got %v", actual)
+	}
+	if encoded = encodeMVTGeometries(NewLineString([][]float64{{1.2, 1.2}, {0.9, 0.9}})); len(encoded) != 0 {
+		t.Errorf("Expected a line with no length to be dropped, got %v", encoded)
+	}
+}
+
+func TestMVTRoundTrip(t *testing.T) {
+	tile := TileForPoint(10, 10, 4)
+	bbox := tile.BoundingBox()
+	west, south, east, north := bbox[0], bbox[1], bbox[2], bbox[3]
+	middleX, middleY := (west+east)/2, (south+north)/2
+
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{middleX, middleY}), 7, map[string]interface{}{
+			"name": "centre", "count": 3.0, "negative": -4, "ratio": 0.25, "flag": true,
+			"missing": nil, "list": []interface{}{1.0, "a"}}),
+		NewFeature(NewLineString([][]float64{{west - 10, middleY}, {middleX, middleY}}), "text id", map[string]interface{}{"name": "road"}),
+		NewFeature(NewPolygon([][][]float64{
+			{{west + 1, south + 1}, {east - 1, south + 1}, {east - 1, north - 1}, {west + 1, north - 1}, {west + 1, south + 1}},
+			{{middleX - 1, middleY - 1}, {middleX - 1, middleY + 1}, {middleX + 1, middleY + 1}, {middleX + 1, middleY - 1}, {middleX - 1, middleY - 1}}}),
+			nil, map[string]interface{}{"name": "area"}),
+		NewFeature(NewPoint([]float64{west - 20, middleY}), nil, map[string]interface{}{"name": "outside"})})
+
+	data, err := EncodeMVT(fc, "features", tile, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := DecodeMVT(data, tile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, ok := layers["features"]
+	if !ok || len(layers) != 1 {
+		t.Fatalf("Expected a single layer called features, got %v", layers)
+	}
+	if len(decoded.Features) != 3 {
+		t.Fatalf("Expected 3 features, got %v", decoded.String())
+	}
+
+	// One tile unit in degrees, for comparing positions
+	tolerance := (east - west) / 4096
+	point := decoded.Features[0]
+	if point.ID != 7.0 {
+		t.Errorf("Expected ID 7, got %#v", point.ID)
+	}
+	if !EqualsWithin(point.Geometry, NewPoint([]float64{middleX, middleY}), tolerance) {
+		t.Errorf("Unexpected point %v", point.Geometry)
+	}
+	expected := map[string]interface{}{"name": "centre", "count": 3.0, "negative": -4.0, "ratio": 0.25, "flag": true, "list": `[1,"a"]`}
+	if len(point.Properties) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, point.Properties)
+	}
+	for key, value := range expected {
+		if point.Properties[key] != value {
+			t.Errorf("Expected %v to be %#v, got %#v", key, value, point.Properties[key])
+		}
+	}
+
+	// The line is clipped to the tile buffer
+	line := decoded.Features[1]
+	if line.ID != nil || line.PropertyString("name") != "road" {
+		t.Errorf("Unexpected line feature %v", line.String())
+	}
+	coordinates := line.Geometry.(*LineString).Coordinates
+	testClose(t, "clipped line start", west-(east-west)/64, coordinates[0][0], tolerance)
+	testClose(t, "line end", middleX, coordinates[1][0], tolerance)
+
+	polygon := decoded.Features[2].Geometry.(*Polygon)
+	if len(polygon.Coordinates) != 2 || ringArea(polygon.Coordinates[0]) <= 0 || ringArea(polygon.Coordinates[1]) >= 0 {
+		t.Errorf("Expected a counterclockwise polygon with a clockwise hole, got %v", polygon.String())
+	}
+	if !EqualsTopologically(polygon, fc.Features[2].Geometry, tolerance) {
+		t.Errorf("Expected %v, got %v", fc.Features[2].Geometry, polygon)
+	}
+}
+
+func TestMVTMultiPolygonAndErrors(t *testing.T) {
+	tile := Tile{X: 0, Y: 0, Z: 0}
+	multi := NewMultiPolygon([][][][]float64{
+		{{{-100, -40}, {-60, -40}, {-60, 0}, {-100, 0}, {-100, -40}}},
+		{{{60, 0}, {100, 0}, {100, 40}, {60, 40}, {60, 0}}}})
+	data, err := EncodeMVT(NewFeatureCollection([]*Feature{NewFeature(multi, 1, nil)}), "multi", tile, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := DecodeMVT(data, tile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := layers["multi"].Features[0].Geometry
+	if !EqualsTopologically(decoded, multi, 360.0/4096) {
+		t.Errorf("Expected %v, got %v", multi, decoded)
+	}
+
+	if _, err = DecodeMVT(data[:len(data)-3], tile); err == nil {
+		t.Error("Expected an error for a truncated tile")
+	}
+	if _, err = EncodeMVT(nil, "empty", tile, 0); err == nil {
+		t.Error("Expected an error for a nil FeatureCollection")
+	}
+}
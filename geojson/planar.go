@@ -0,0 +1,168 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "math"
+
+// This file contains the planar (Cartesian) primitives
+// shared by the geometry algorithms in this package.
+// Only the first two ordinates of each position are considered.
+
+// cross returns the z component of (b - a) x (c - a), which is
+// positive if a, b, c turn counterclockwise and zero if they are collinear
+func cross(a, b, c []float64) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// onSegment returns true if p lies on the segment from a to b
+func onSegment(p, a, b []float64) bool {
+	return cross(a, b, p) == 0 &&
+		math.Min(a[0], b[0]) <= p[0] && p[0] <= math.Max(a[0], b[0]) &&
+		math.Min(a[1], b[1]) <= p[1] && p[1] <= math.Max(a[1], b[1])
+}
+
+// segmentIntersection returns a position where the segments a-b and c-d meet,
+// or nil if they do not. When collinear segments overlap, the returned
+// position is the start of the overlap.
+func segmentIntersection(a, b, c, d []float64) []float64 {
+	d1 := cross(c, d, a)
+	d2 := cross(c, d, b)
+	d3 := cross(a, b, c)
+	d4 := cross(a, b, d)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return interpolatePosition(a, b, d1/(d1-d2))
+	}
+	for _, candidate := range [][]float64{a, b, c, d} {
+		if onSegment(candidate, a, b) && onSegment(candidate, c, d) {
+			return clone1(candidate)
+		}
+	}
+	return nil
+}
+
+// segmentsCross returns true if the segments a-b and c-d cross
+// at a single point interior to both
+func segmentsCross(a, b, c, d []float64) bool {
+	d1 := cross(c, d, a)
+	d2 := cross(c, d, b)
+	d3 := cross(a, b, c)
+	d4 := cross(a, b, d)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// segmentsOverlap returns true if the segments a-b and c-d are collinear
+// and share more than a single point
+func segmentsOverlap(a, b, c, d []float64) bool {
+	if cross(a, b, c) != 0 || cross(a, b, d) != 0 {
+		return false
+	}
+	// Project onto the longer axis of a-b
+	axis := 0
+	if math.Abs(b[1]-a[1]) > math.Abs(b[0]-a[0]) {
+		axis = 1
+	}
+	low1, high1 := math.Min(a[axis], b[axis]), math.Max(a[axis], b[axis])
+	low2, high2 := math.Min(c[axis], d[axis]), math.Max(c[axis], d[axis])
+	return math.Min(high1, high2) > math.Max(low1, low2)
+}
+
+// pointInRing returns 1 if the position is inside the ring,
+// 0 if it is on the boundary, and -1 if it is outside.
+// The ring may or may not repeat its first position at the end.
+func pointInRing(p []float64, ring [][]float64) int {
+	inside := false
+	length := len(ring)
+	for i, j := 0, length-1; i < length; j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if onSegment(p, a, b) {
+			return 0
+		}
+		if (a[1] > p[1]) != (b[1] > p[1]) &&
+			p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	if inside {
+		return 1
+	}
+	return -1
+}
+
+// pointInPolygon returns 1 if the position is in the interior of the polygon,
+// 0 if it is on the boundary of any ring, and -1 if it is outside or in a hole
+func pointInPolygon(p []float64, polygon [][][]float64) int {
+	if len(polygon) == 0 {
+		return -1
+	}
+	result := pointInRing(p, polygon[0])
+	if result != 1 {
+		return result
+	}
+	for _, hole := range polygon[1:] {
+		switch pointInRing(p, hole) {
+		case 0:
+			return 0
+		case 1:
+			return -1
+		}
+	}
+	return 1
+}
+
+// ringInsideRing returns true if the inner ring lies inside the outer ring,
+// allowing the two to touch
+func ringInsideRing(inner, outer [][]float64) bool {
+	var inside, outside int
+	for _, position := range inner {
+		switch pointInRing(position, outer) {
+		case 1:
+			inside++
+		case -1:
+			outside++
+		}
+	}
+	if outside > 0 {
+		return false
+	}
+	if inside > 0 {
+		return true
+	}
+	// Every position is on the boundary, so try a point in the middle
+	return pointInRing(ringCentroid(inner), outer) == 1
+}
+
+// ringCentroid returns the area-weighted centroid of a ring,
+// or the mean of its positions if it has no area
+func ringCentroid(ring [][]float64) []float64 {
+	var area, x, y float64
+	for inx := range ring {
+		curr := ring[inx]
+		next := ring[(inx+1)%len(ring)]
+		f := curr[0]*next[1] - next[0]*curr[1]
+		area += f
+		x += (curr[0] + next[0]) * f
+		y += (curr[1] + next[1]) * f
+	}
+	if area == 0 {
+		for _, position := range ring {
+			x += position[0]
+			y += position[1]
+		}
+		return []float64{x / float64(len(ring)), y / float64(len(ring))}
+	}
+	return []float64{x / (3 * area), y / (3 * area)}
+}
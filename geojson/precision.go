@@ -0,0 +1,315 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// SetPrecision returns a copy of the GeoJSON object with every ordinate
+// rounded to the number of decimal places provided.
+// Consecutive duplicate positions that result are removed, as are lines
+// and rings that collapse; a Polygon whose exterior ring collapses becomes empty.
+// The result is nil if the object is not a GeoJSON object.
+func SetPrecision(gjObject interface{}, decimals int) interface{} {
+	result, _ := MapCoordinates(gjObject, roundPosition(decimals))
+	return cleanGeometry(result)
+}
+
+// SnapToGrid returns a copy of the GeoJSON object with every ordinate
+// moved to the nearest multiple of the grid size provided,
+// cleaning up the result in the same way as SetPrecision
+func SnapToGrid(gjObject interface{}, gridSize float64) interface{} {
+	if gridSize <= 0 {
+		result, _ := MapCoordinates(gjObject, func(position []float64) []float64 { return position })
+		return result
+	}
+	result, _ := MapCoordinates(gjObject, func(position []float64) []float64 {
+		for inx, value := range position {
+			position[inx] = math.Round(value/gridSize) * gridSize
+		}
+		return position
+	})
+	return cleanGeometry(result)
+}
+
+func roundPosition(decimals int) func([]float64) []float64 {
+	scale := math.Pow(10, float64(decimals))
+	return func(position []float64) []float64 {
+		for inx, value := range position {
+			position[inx] = math.Round(value*scale) / scale
+		}
+		return position
+	}
+}
+
+// cleanGeometry removes consecutive duplicate positions from the object,
+// along with the lines and rings that no longer have enough positions
+func cleanGeometry(gjObject interface{}) interface{} {
+	switch typedGJ := gjObject.(type) {
+	case *LineString:
+		typedGJ.Coordinates = cleanLine(typedGJ.Coordinates)
+	case *Polygon:
+		typedGJ.Coordinates = cleanPolygon(typedGJ.Coordinates)
+	case *MultiLineString:
+		lines := [][][]float64{}
+		for _, line := range typedGJ.Coordinates {
+			if line = cleanLine(line); len(line) > 0 {
+				lines = append(lines, line)
+			}
+		}
+		typedGJ.Coordinates = lines
+	case *MultiPolygon:
+		polygons := [][][][]float64{}
+		for _, polygon := range typedGJ.Coordinates {
+			if polygon = cleanPolygon(polygon); len(polygon) > 0 {
+				polygons = append(polygons, polygon)
+			}
+		}
+		typedGJ.Coordinates = polygons
+	case *GeometryCollection:
+		for inx, geometry := range typedGJ.Geometries {
+			typedGJ.Geometries[inx] = cleanGeometry(geometry)
+		}
+	case *Feature:
+		typedGJ.Geometry = cleanGeometry(typedGJ.Geometry)
+	case *FeatureCollection:
+		for _, feature := range typedGJ.Features {
+			cleanGeometry(feature)
+		}
+	}
+	return gjObject
+}
+
+func removeDuplicatePositions(positions [][]float64) [][]float64 {
+	var result [][]float64
+	for _, position := range positions {
+		if len(result) == 0 || !samePosition(position, result[len(result)-1]) {
+			result = append(result, position)
+		}
+	}
+	return result
+}
+
+func cleanLine(line [][]float64) [][]float64 {
+	if line = removeDuplicatePositions(line); len(line) < 2 {
+		return [][]float64{}
+	}
+	return line
+}
+
+func cleanPolygon(polygon [][][]float64) [][][]float64 {
+	result := [][][]float64{}
+	for inx, ring := range polygon {
+		ring = removeDuplicatePositions(ring)
+		if len(ring) < 4 || ringArea(ring) == 0 {
+			if inx == 0 {
+				// Without an exterior ring there is no polygon
+				return result
+			}
+			continue
+		}
+		result = append(result, ring)
+	}
+	return result
+}
+
+// fixedPrecision returns a copy of the GeoJSON object that marshals its
+// coordinates and bounding boxes with exactly the number of decimals provided
+func fixedPrecision(gjObject interface{}, decimals int) (interface{}, error) {
+	switch typed := gjObject.(type) {
+	case *Point, *LineString, *Polygon, *MultiPoint, *MultiLineString, *MultiPolygon:
+		return fixedGeometry(typed, decimals), nil
+	case *GeometryCollection:
+		if typed == nil {
+			return nil, nil
+		}
+		geometries := make([]interface{}, len(typed.Geometries))
+		for inx, geometry := range typed.Geometries {
+			var err error
+			if geometries[inx], err = fixedPrecision(newGeometry(geometry), decimals); err != nil {
+				return nil, err
+			}
+		}
+		return fixedCollection{Type: typed.Type, Geometries: geometries, Bbox: fixedBbox(typed.Bbox, decimals)}, nil
+	case *Feature:
+		if typed == nil {
+			return nil, nil
+		}
+		geometry, err := fixedPrecision(newGeometry(typed.Geometry), decimals)
+		if err != nil {
+			return nil, err
+		}
+		return fixedFeature{Type: typed.Type, Geometry: geometry, Properties: typed.Properties, ID: typed.ID, Bbox: fixedBbox(typed.Bbox, decimals)}, nil
+	case *FeatureCollection:
+		if typed == nil {
+			return nil, nil
+		}
+		features := make([]interface{}, len(typed.Features))
+		for inx, feature := range typed.Features {
+			var err error
+			if features[inx], err = fixedPrecision(feature, decimals); err != nil {
+				return nil, err
+			}
+		}
+		return fixedFeatureCollection{Type: typed.Type, Features: features, Bbox: fixedBbox(typed.Bbox, decimals)}, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("Cannot write %T with a fixed precision", gjObject)
+}
+
+type fixedGeometryObject struct {
+	Type        string         `json:"type"`
+	Coordinates fixedNumbers   `json:"coordinates"`
+	Bbox        json.Marshaler `json:"bbox,omitempty"`
+}
+
+type fixedCollection struct {
+	Type       string         `json:"type"`
+	Geometries []interface{}  `json:"geometries"`
+	Bbox       json.Marshaler `json:"bbox,omitempty"`
+}
+
+type fixedFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   interface{}            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+	ID         interface{}            `json:"id,omitempty"`
+	Bbox       json.Marshaler         `json:"bbox,omitempty"`
+}
+
+type fixedFeatureCollection struct {
+	Type     string         `json:"type"`
+	Features []interface{}  `json:"features"`
+	Bbox     json.Marshaler `json:"bbox,omitempty"`
+}
+
+func fixedGeometry(geometry interface{}, decimals int) interface{} {
+	var (
+		result      fixedGeometryObject
+		coordinates interface{}
+		bbox        BoundingBox
+	)
+	switch typed := geometry.(type) {
+	case *Point:
+		if typed == nil {
+			return nil
+		}
+		result.Type, coordinates, bbox = typed.Type, typed.Coordinates, typed.Bbox
+	case *LineString:
+		if typed == nil {
+			return nil
+		}
+		result.Type, coordinates, bbox = typed.Type, typed.Coordinates, typed.Bbox
+	case *Polygon:
+		if typed == nil {
+			return nil
+		}
+		result.Type, coordinates, bbox = typed.Type, typed.Coordinates, typed.Bbox
+	case *MultiPoint:
+		if typed == nil {
+			return nil
+		}
+		result.Type, coordinates, bbox = typed.Type, typed.Coordinates, typed.Bbox
+	case *MultiLineString:
+		if typed == nil {
+			return nil
+		}
+		result.Type, coordinates, bbox = typed.Type, typed.Coordinates, typed.Bbox
+	case *MultiPolygon:
+		if typed == nil {
+			return nil
+		}
+		result.Type, coordinates, bbox = typed.Type, typed.Coordinates, typed.Bbox
+	}
+	result.Coordinates = fixedNumbers{coordinates, decimals}
+	result.Bbox = fixedBbox(bbox, decimals)
+	return result
+}
+
+// fixedBbox returns nil for an empty bounding box so that it is omitted
+func fixedBbox(bbox BoundingBox, decimals int) json.Marshaler {
+	if len(bbox) == 0 {
+		return nil
+	}
+	return fixedNumbers{[]float64(bbox), decimals}
+}
+
+// fixedNumbers marshals a coordinate array of any depth
+// with a fixed number of decimals
+type fixedNumbers struct {
+	numbers  interface{}
+	decimals int
+}
+
+// MarshalJSON writes the numbers with the fixed number of decimals
+func (numbers fixedNumbers) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := writeFixedNumbers(&buffer, numbers.numbers, numbers.decimals); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func writeFixedNumbers(buffer *bytes.Buffer, numbers interface{}, decimals int) error {
+	switch typed := numbers.(type) {
+	case []float64:
+		return writeFixedArray(buffer, typed == nil, len(typed), func(inx int) error {
+			if math.IsNaN(typed[inx]) || math.IsInf(typed[inx], 0) {
+				return fmt.Errorf("Cannot write the coordinate %v", typed[inx])
+			}
+			buffer.WriteString(strconv.FormatFloat(typed[inx], 'f', decimals, 64))
+			return nil
+		})
+	case [][]float64:
+		return writeFixedArray(buffer, typed == nil, len(typed), func(inx int) error {
+			return writeFixedNumbers(buffer, typed[inx], decimals)
+		})
+	case [][][]float64:
+		return writeFixedArray(buffer, typed == nil, len(typed), func(inx int) error {
+			return writeFixedNumbers(buffer, typed[inx], decimals)
+		})
+	case [][][][]float64:
+		return writeFixedArray(buffer, typed == nil, len(typed), func(inx int) error {
+			return writeFixedNumbers(buffer, typed[inx], decimals)
+		})
+	}
+	return fmt.Errorf("Cannot write coordinates of type %T", numbers)
+}
+
+func writeFixedArray(buffer *bytes.Buffer, isNil bool, length int, member func(int) error) error {
+	if isNil {
+		buffer.WriteString("null")
+		return nil
+	}
+	buffer.WriteByte('[')
+	for inx := 0; inx < length; inx++ {
+		if inx > 0 {
+			buffer.WriteByte(',')
+		}
+		if err := member(inx); err != nil {
+			return err
+		}
+	}
+	buffer.WriteByte(']')
+	return nil
+}
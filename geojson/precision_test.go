@@ -0,0 +1,87 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetPrecision(t *testing.T) {
+	polygon := NewPolygon([][][]float64{
+		{{0.00001, 0}, {1.00004, 0.00002}, {1, 1}, {1.00001, 1.00001}, {0, 1.000049}, {0, 0}},
+		{{0.5, 0.5}, {0.50001, 0.5}, {0.50001, 0.50001}, {0.5, 0.5}}})
+	result := SetPrecision(polygon, 3).(*Polygon)
+	expected := `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`
+	if result.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, result.String())
+	}
+	if polygon.Coordinates[0][0][0] != 0.00001 {
+		t.Error("SetPrecision modified its input")
+	}
+
+	mls := NewMultiLineString([][][]float64{{{0.1, 0.1}, {0.2, 0.2}}, {{1.26, 1.24}, {3.14159, 2.71828}}})
+	expected = `{"type":"MultiLineString","coordinates":[[[1,1],[3,3]]]}`
+	if result := SetPrecision(mls, 0).(*MultiLineString); result.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, result.String())
+	}
+
+	feature := NewFeature(NewPoint([]float64{123.456, -7.891}), 1, nil)
+	expected = `{"type":"Feature","geometry":{"type":"Point","coordinates":[125,-10]},"properties":{},"id":1}`
+	if result := SnapToGrid(feature, 5).(*Feature); result.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, result.String())
+	}
+}
+
+func TestWriteWithPrecision(t *testing.T) {
+	ls := NewLineString([][]float64{{1.23456789, 2.3456789}, {1.23456789, 2.3456789}, {3.000001, 4}})
+	ls.Bbox = ls.ForceBbox()
+	bytes, err := Write(ls, WithPrecision(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"LineString","coordinates":[[1.23,2.35],[1.23,2.35],[3.00,4.00]],"bbox":[1.23,2.35,3.00,4.00]}`
+	if string(bytes) != expected {
+		t.Errorf("Expected %v, got %v", expected, string(bytes))
+	}
+
+	feature := NewFeature(NewGeometryCollection([]interface{}{NewPoint([]float64{1.5, -2}), NewLineString([][]float64{{0.04, 0.06}, {1, 1}})}), "a", map[string]interface{}{"value": 1.23456})
+	fc := NewFeatureCollection([]*Feature{feature, NewFeature(nil, nil, nil)})
+	if bytes, err = Write(fc, WithPrecision(1)); err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1.5,-2.0]},{"type":"LineString","coordinates":[[0.0,0.1],[1.0,1.0]]}]},"properties":{"value":1.23456},"id":"a"},{"type":"Feature","geometry":null,"properties":{}}]}`
+	if string(bytes) != expected {
+		t.Errorf("Expected %v, got %v", expected, string(bytes))
+	}
+	var parsed interface{}
+	if parsed, err = Parse(bytes); err != nil || parsed.(*FeatureCollection).Features[0].IDStr() != "a" {
+		t.Errorf("Expected the output to parse, got %v", err)
+	}
+
+	if bytes, err = Write(ls); err != nil || string(bytes) != ls.String() {
+		t.Errorf("Expected the default output without options, got %v %v", string(bytes), err)
+	}
+	for _, input := range []interface{}{map[string]interface{}{"foo": 1.23456}, NewPoint([]float64{math.NaN(), 0})} {
+		if bytes, err = Write(input, WithPrecision(2)); err == nil {
+			t.Errorf("Expected an error writing %v, got %v", input, string(bytes))
+		}
+	}
+	if _, err = Write(ls, WithPrecision(-1)); err == nil {
+		t.Error("Expected an error for negative decimals")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+// The predicates in this file are planar and accept geometries, Features
+// and FeatureCollections. Objects without positions satisfy none of them.
+
+// Intersects returns true if the two objects share any point,
+// including when one lies inside a polygon of the other
+func Intersects(a, b interface{}) bool {
+	partsA, partsB := decompose(a), decompose(b)
+	if partsA.empty() || partsB.empty() {
+		return false
+	}
+	return partsA.intersection(partsB) != nil
+}
+
+// Contains returns true if every point of b lies in a or on its boundary
+func Contains(a, b interface{}) bool {
+	partsA, partsB := decompose(a), decompose(b)
+	if partsA.empty() || partsB.empty() {
+		return false
+	}
+	// Only polygons can cover an area
+	if len(partsB.polygons) > 0 && len(partsA.polygons) == 0 {
+		return false
+	}
+	for _, point := range partsB.points {
+		if !partsA.covers(point) {
+			return false
+		}
+	}
+	for _, segment := range partsB.segments {
+		middle := interpolatePosition(segment[0], segment[1], 0.5)
+		if !partsA.covers(segment[0]) || !partsA.covers(segment[1]) || !partsA.covers(middle) {
+			return false
+		}
+		for _, boundary := range partsA.segments {
+			if segmentsCross(segment[0], segment[1], boundary[0], boundary[1]) {
+				return false
+			}
+		}
+	}
+	// A polygon of b must not surround a hole of a
+	for _, polygonA := range partsA.polygons {
+		for _, hole := range polygonA[1:] {
+			for _, polygonB := range partsB.polygons {
+				for _, position := range hole {
+					if pointInPolygon(position, polygonB) == 1 {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Within returns true if every point of a lies in b or on its boundary
+func Within(a, b interface{}) bool {
+	return Contains(b, a)
+}
+
+// covers returns true if the position lies on or in any of the parts
+func (parts geometryParts) covers(position []float64) bool {
+	for _, point := range parts.points {
+		if samePosition(point, position) {
+			return true
+		}
+	}
+	for _, segment := range parts.segments {
+		if onSegment(position, segment[0], segment[1]) {
+			return true
+		}
+	}
+	for _, polygon := range parts.polygons {
+		if pointInPolygon(position, polygon) >= 0 {
+			return true
+		}
+	}
+	return false
+}
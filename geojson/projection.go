@@ -0,0 +1,297 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Coordinate reference system constants
+const (
+	EPSG4326 = "EPSG:4326"
+	EPSG3857 = "EPSG:3857"
+)
+
+// WGS84 ellipsoid parameters
+const (
+	wgs84SemiMajorAxis = 6378137.0
+	wgs84Flattening    = 1 / 298.257223563
+)
+
+// MaxMercatorLatitude is the latitude at which Web Mercator becomes square.
+// Latitudes beyond it are clamped when projecting to EPSG:3857.
+const MaxMercatorLatitude = 85.05112877980659
+
+// The Projection interface converts positions between a coordinate reference system
+// and WGS84 longitude/latitude (EPSG:4326).
+// Dimensions beyond the first two, such as elevation, are passed through.
+type Projection interface {
+	// Forward converts a longitude/latitude position into this projection
+	Forward(position []float64) ([]float64, error)
+	// Inverse converts a position in this projection into longitude/latitude
+	Inverse(position []float64) ([]float64, error)
+	// IsGeographic returns true if positions in this projection are
+	// longitude/latitude, so that bounding boxes may cross the antimeridian
+	IsGeographic() bool
+}
+
+var (
+	projections = map[string]Projection{
+		EPSG4326:      wgs84Projection{},
+		"EPSG:900913": webMercatorProjection{},
+		EPSG3857:      webMercatorProjection{}}
+	projectionsMutex sync.RWMutex
+)
+
+// RegisterProjection makes a Projection available to GetProjection
+// under the code provided, replacing any existing registration
+func RegisterProjection(code string, projection Projection) {
+	projectionsMutex.Lock()
+	defer projectionsMutex.Unlock()
+	projections[strings.ToUpper(code)] = projection
+}
+
+// GetProjection returns the Projection for a code such as "EPSG:3857".
+// UTM zones are available as EPSG:326zz (north) and EPSG:327zz (south).
+func GetProjection(code string) (Projection, error) {
+	code = strings.ToUpper(code)
+	projectionsMutex.RLock()
+	projection, ok := projections[code]
+	projectionsMutex.RUnlock()
+	if ok {
+		return projection, nil
+	}
+	if strings.HasPrefix(code, "EPSG:326") || strings.HasPrefix(code, "EPSG:327") {
+		if zone, err := strconv.Atoi(code[8:]); err == nil {
+			return NewUTMProjection(zone, code[7] == '7')
+		}
+	}
+	return nil, fmt.Errorf("Unknown projection %v", code)
+}
+
+// Transform returns a copy of the GeoJSON object with every position
+// reprojected from one Projection to another.
+// Any bbox members present on the input are recomputed in the new projection:
+// as plain rectangles for projected coordinates, or allowing them to cross
+// the antimeridian when the target projection is geographic.
+func Transform(gjObject interface{}, from, to Projection) (interface{}, error) {
+	if from == nil || to == nil {
+		return nil, errors.New("Transform requires both a source and a target projection")
+	}
+	bbox := planarBbox
+	if to.IsGeographic() {
+		bbox = geographicBbox
+	}
+	return mapPositions(gjObject, func(position []float64) ([]float64, error) {
+		lonLat, err := from.Inverse(position)
+		if err != nil {
+			return nil, err
+		}
+		return to.Forward(lonLat)
+	}, bbox)
+}
+
+func checkPosition(position []float64) error {
+	if len(position) < 2 {
+		return fmt.Errorf("Position %v must have at least two values", position)
+	}
+	return nil
+}
+
+// wgs84Projection is EPSG:4326, in which positions are already longitude/latitude
+type wgs84Projection struct{}
+
+func (wgs84Projection) Forward(position []float64) ([]float64, error) {
+	if err := checkPosition(position); err != nil {
+		return nil, err
+	}
+	return append([]float64{}, position...), nil
+}
+
+func (wgs84Projection) Inverse(position []float64) ([]float64, error) {
+	return wgs84Projection{}.Forward(position)
+}
+
+func (wgs84Projection) IsGeographic() bool {
+	return true
+}
+
+// webMercatorProjection is EPSG:3857, the spherical Mercator used by web map tiles
+type webMercatorProjection struct{}
+
+func (webMercatorProjection) Forward(position []float64) ([]float64, error) {
+	if err := checkPosition(position); err != nil {
+		return nil, err
+	}
+	latitude := math.Max(-MaxMercatorLatitude, math.Min(MaxMercatorLatitude, position[1]))
+	result := append([]float64{}, position...)
+	result[0] = wgs84SemiMajorAxis * position[0] * math.Pi / 180
+	result[1] = wgs84SemiMajorAxis * math.Log(math.Tan(math.Pi/4+latitude*math.Pi/360))
+	return result, nil
+}
+
+func (webMercatorProjection) IsGeographic() bool {
+	return false
+}
+
+func (webMercatorProjection) Inverse(position []float64) ([]float64, error) {
+	if err := checkPosition(position); err != nil {
+		return nil, err
+	}
+	result := append([]float64{}, position...)
+	result[0] = position[0] / wgs84SemiMajorAxis * 180 / math.Pi
+	result[1] = (2*math.Atan(math.Exp(position[1]/wgs84SemiMajorAxis)) - math.Pi/2) * 180 / math.Pi
+	return result, nil
+}
+
+// utmProjection is a Universal Transverse Mercator zone on the WGS84 ellipsoid,
+// computed with the Krüger series as given by Karney (2011)
+type utmProjection struct {
+	zone            int
+	south           bool
+	centralMeridian float64
+}
+
+const (
+	utmScale         = 0.9996
+	utmFalseEasting  = 500000.0
+	utmFalseNorthing = 10000000.0
+)
+
+var (
+	utmE, utmA          float64
+	utmAlpha, utmBeta   [6]float64
+	utmCoefficientsOnce sync.Once
+)
+
+func initUTMCoefficients() {
+	n := wgs84Flattening / (2 - wgs84Flattening)
+	n2, n3, n4, n5, n6 := n*n, n*n*n, n*n*n*n, n*n*n*n*n, n*n*n*n*n*n
+	utmE = math.Sqrt(wgs84Flattening * (2 - wgs84Flattening))
+	utmA = wgs84SemiMajorAxis / (1 + n) * (1 + n2/4 + n4/64 + n6/256)
+	utmAlpha = [6]float64{
+		n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+		13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+		61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+		49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+		34729*n5/80640 - 3418889*n6/1995840,
+		212378941 * n6 / 319334400}
+	utmBeta = [6]float64{
+		n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+		n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+		17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+		4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+		4583*n5/161280 - 108847*n6/3991680,
+		20648693 * n6 / 638668800}
+}
+
+// NewUTMProjection returns the Projection for a UTM zone (1-60)
+// in the northern or southern hemisphere
+func NewUTMProjection(zone int, south bool) (Projection, error) {
+	if zone < 1 || zone > 60 {
+		return nil, fmt.Errorf("UTM zone %v must be between 1 and 60", zone)
+	}
+	utmCoefficientsOnce.Do(initUTMCoefficients)
+	return utmProjection{zone: zone, south: south, centralMeridian: float64(zone*6 - 183)}, nil
+}
+
+func (utm utmProjection) Forward(position []float64) ([]float64, error) {
+	if err := checkPosition(position); err != nil {
+		return nil, err
+	}
+	longitude := normalizeLongitude(position[0]-utm.centralMeridian) * math.Pi / 180
+	latitude := position[1] * math.Pi / 180
+	if math.Abs(longitude) >= math.Pi/2 || math.Abs(latitude) > math.Pi/2 {
+		return nil, fmt.Errorf("Position %v cannot be projected into UTM zone %v", position, utm.zone)
+	}
+
+	tau := math.Tan(latitude)
+	sigma := math.Sinh(utmE * math.Atanh(utmE*tau/math.Sqrt(1+tau*tau)))
+	tauPrime := tau*math.Sqrt(1+sigma*sigma) - sigma*math.Sqrt(1+tau*tau)
+	xiPrime := math.Atan2(tauPrime, math.Cos(longitude))
+	etaPrime := math.Asinh(math.Sin(longitude) / math.Sqrt(tauPrime*tauPrime+math.Cos(longitude)*math.Cos(longitude)))
+
+	xi, eta := xiPrime, etaPrime
+	for inx, alpha := range utmAlpha {
+		j := float64(2 * (inx + 1))
+		xi += alpha * math.Sin(j*xiPrime) * math.Cosh(j*etaPrime)
+		eta += alpha * math.Cos(j*xiPrime) * math.Sinh(j*etaPrime)
+	}
+
+	result := append([]float64{}, position...)
+	result[0] = utmScale*utmA*eta + utmFalseEasting
+	result[1] = utmScale * utmA * xi
+	if utm.south {
+		result[1] += utmFalseNorthing
+	}
+	return result, nil
+}
+
+func (utmProjection) IsGeographic() bool {
+	return false
+}
+
+func (utm utmProjection) Inverse(position []float64) ([]float64, error) {
+	if err := checkPosition(position); err != nil {
+		return nil, err
+	}
+	northing := position[1]
+	if utm.south {
+		northing -= utmFalseNorthing
+	}
+	eta := (position[0] - utmFalseEasting) / (utmScale * utmA)
+	xi := northing / (utmScale * utmA)
+
+	xiPrime, etaPrime := xi, eta
+	for inx, beta := range utmBeta {
+		j := float64(2 * (inx + 1))
+		xiPrime -= beta * math.Sin(j*xi) * math.Cosh(j*eta)
+		etaPrime -= beta * math.Cos(j*xi) * math.Sinh(j*eta)
+	}
+
+	sinhEtaPrime := math.Sinh(etaPrime)
+	sinXiPrime := math.Sin(xiPrime)
+	cosXiPrime := math.Cos(xiPrime)
+	tauPrime := sinXiPrime / math.Sqrt(sinhEtaPrime*sinhEtaPrime+cosXiPrime*cosXiPrime)
+
+	// Newton-Raphson iteration for the conformal latitude
+	e2 := utmE * utmE
+	tau := tauPrime
+	for iteration := 0; iteration < 20; iteration++ {
+		sigma := math.Sinh(utmE * math.Atanh(utmE*tau/math.Sqrt(1+tau*tau)))
+		tauI := tau*math.Sqrt(1+sigma*sigma) - sigma*math.Sqrt(1+tau*tau)
+		delta := (tauPrime - tauI) / math.Sqrt(1+tauI*tauI) *
+			(1 + (1-e2)*tau*tau) / ((1 - e2) * math.Sqrt(1+tau*tau))
+		tau += delta
+		if math.Abs(delta) < 1e-12 {
+			break
+		}
+	}
+
+	result := append([]float64{}, position...)
+	result[0] = normalizeLongitude(math.Atan2(sinhEtaPrime, cosXiPrime)*180/math.Pi + utm.centralMeridian)
+	result[1] = math.Atan(tau) * 180 / math.Pi
+	if math.IsNaN(result[0]) || math.IsNaN(result[1]) {
+		return nil, fmt.Errorf("Position %v cannot be unprojected from UTM zone %v", position, utm.zone)
+	}
+	return result, nil
+}
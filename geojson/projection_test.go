@@ -0,0 +1,202 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func testClose(t *testing.T, name string, expected, actual, tolerance float64) {
+	if math.Abs(expected-actual) > tolerance {
+		t.Errorf("%v: expected %v, got %v", name, expected, actual)
+	}
+}
+
+func TestProjections(t *testing.T) {
+	var (
+		err         error
+		wgs84       Projection
+		webMercator Projection
+		utm31N      Projection
+		utm56S      Projection
+		position    []float64
+	)
+	if wgs84, err = GetProjection("epsg:4326"); err != nil {
+		t.Fatal(err)
+	}
+	if webMercator, err = GetProjection(EPSG3857); err != nil {
+		t.Fatal(err)
+	}
+	if utm31N, err = GetProjection("EPSG:32631"); err != nil {
+		t.Fatal(err)
+	}
+	if utm56S, err = GetProjection("EPSG:32756"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = GetProjection("EPSG:32661"); err == nil {
+		t.Error("Expected an error for UTM zone 61")
+	}
+	if _, err = GetProjection("EPSG:1234"); err == nil {
+		t.Error("Expected an error for an unknown projection")
+	}
+
+	if position, err = webMercator.Forward([]float64{180, 0, 12}); err != nil {
+		t.Fatal(err)
+	}
+	testClose(t, "Web Mercator easting", 20037508.342789244, position[0], 1e-6)
+	testClose(t, "Web Mercator northing", 0, position[1], 1e-6)
+	testClose(t, "Web Mercator elevation", 12, position[2], 0)
+	if position, err = webMercator.Forward([]float64{0, 90}); err != nil {
+		t.Fatal(err)
+	}
+	testClose(t, "Web Mercator clamped northing", 20037508.342789244, position[1], 1e-6)
+
+	// The Eiffel Tower
+	if position, err = utm31N.Forward([]float64{2.2945, 48.8583}); err != nil {
+		t.Fatal(err)
+	}
+	testClose(t, "UTM easting", 448251.898, position[0], 1e-3)
+	testClose(t, "UTM northing", 5411943.794, position[1], 1e-3)
+	if position, err = utm31N.Inverse(position); err != nil {
+		t.Fatal(err)
+	}
+	testClose(t, "UTM longitude", 2.2945, position[0], 1e-9)
+	testClose(t, "UTM latitude", 48.8583, position[1], 1e-9)
+
+	// The Sydney Opera House
+	if position, err = utm56S.Forward([]float64{151.2153, -33.8568}); err != nil {
+		t.Fatal(err)
+	}
+	if position, err = utm56S.Inverse(position); err != nil {
+		t.Fatal(err)
+	}
+	testClose(t, "UTM south longitude", 151.2153, position[0], 1e-9)
+	testClose(t, "UTM south latitude", -33.8568, position[1], 1e-9)
+
+	if position, err = wgs84.Forward([]float64{1}); err == nil {
+		t.Errorf("Expected an error for a short position, got %v", position)
+	}
+}
+
+type offsetProjection struct{}
+
+func (offsetProjection) Forward(position []float64) ([]float64, error) {
+	return []float64{position[0] + 1000, position[1] + 1000}, nil
+}
+
+func (offsetProjection) Inverse(position []float64) ([]float64, error) {
+	return []float64{position[0] - 1000, position[1] - 1000}, nil
+}
+
+func (offsetProjection) IsGeographic() bool {
+	return false
+}
+
+// lonLatProjection is a registered geographic projection other than EPSG:4326
+type lonLatProjection struct{}
+
+func (lonLatProjection) Forward(position []float64) ([]float64, error) {
+	return append([]float64{}, position...), nil
+}
+
+func (lonLatProjection) Inverse(position []float64) ([]float64, error) {
+	return append([]float64{}, position...), nil
+}
+
+func (lonLatProjection) IsGeographic() bool {
+	return true
+}
+
+func TestTransform(t *testing.T) {
+	var (
+		gj          interface{}
+		err         error
+		webMercator Projection
+		offset      Projection
+		wgs84       Projection
+	)
+	RegisterProjection("test:offset", offsetProjection{})
+	if offset, err = GetProjection("TEST:OFFSET"); err != nil {
+		t.Fatal(err)
+	}
+	webMercator, _ = GetProjection(EPSG3857)
+	wgs84, _ = GetProjection(EPSG4326)
+
+	if gj, err = ParseFile("test/featureCollection.geojson"); err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	fc := gj.(*FeatureCollection)
+	fc.Bbox = fc.ForceBbox()
+	if gj, err = Transform(fc, wgs84, offset); err != nil {
+		t.Fatal(err)
+	}
+	result := gj.(*FeatureCollection)
+	if len(result.Features) != len(fc.Features) {
+		t.Fatalf("Expected %v features, got %v", len(fc.Features), len(result.Features))
+	}
+	expected := BoundingBox{fc.Bbox[0] + 1000, fc.Bbox[1] + 1000, fc.Bbox[2] + 1000, fc.Bbox[3] + 1000}
+	for inx := range expected {
+		testClose(t, "Transformed bounding box", expected[inx], result.Bbox[inx], 1e-9)
+	}
+	if point := fc.Features[1].Geometry.(*Point); point.Coordinates[0] > 0 {
+		t.Errorf("Transform modified its input: %v", point)
+	}
+
+	polygon := NewPolygon([][][]float64{{{-10, -10}, {10, -10}, {10, 10}, {-10, 10}, {-10, -10}}})
+	polygon.Bbox = polygon.ForceBbox()
+	if gj, err = Transform(polygon, wgs84, webMercator); err != nil {
+		t.Fatal(err)
+	}
+	bbox := gj.(*Polygon).Bbox
+	testClose(t, "Projected bounding box west", -1113194.9079327357, bbox[0], 1e-6)
+	testClose(t, "Projected bounding box east", 1113194.9079327357, bbox[2], 1e-6)
+	if gj, err = Transform(gj, webMercator, wgs84); err != nil {
+		t.Fatal(err)
+	}
+	testClose(t, "Round trip bounding box south", -10, gj.(*Polygon).Bbox[1], 1e-9)
+
+	// Projected coordinates near the origin are not wrapped like longitudes
+	nearOrigin := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{-0.001, 0}), nil, nil),
+		NewFeature(NewPoint([]float64{0.001, 0}), nil, nil)})
+	nearOrigin.Bbox = nearOrigin.ForceBbox()
+	if gj, err = Transform(nearOrigin, wgs84, webMercator); err != nil {
+		t.Fatal(err)
+	}
+	bbox = gj.(*FeatureCollection).Bbox
+	testClose(t, "Projected collection west", -111.31949079327357, bbox[0], 1e-6)
+	testClose(t, "Projected collection east", 111.31949079327357, bbox[2], 1e-6)
+
+	// Bounding boxes in any geographic projection may cross the antimeridian
+	RegisterProjection("test:lonlat", lonLatProjection{})
+	lonLat, _ := GetProjection("test:lonlat")
+	crossing := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{179, 0}), nil, nil),
+		NewFeature(NewPoint([]float64{-179, 1}), nil, nil)})
+	crossing.Bbox = crossing.ForceBbox()
+	if gj, err = Transform(crossing, wgs84, lonLat); err != nil {
+		t.Fatal(err)
+	}
+	if bbox = gj.(*FeatureCollection).Bbox; !bbox.Equals(BoundingBox{179, 0, -179, 1}) {
+		t.Errorf("Expected a bounding box across the antimeridian, got %v", bbox)
+	}
+
+	if _, err = Transform(polygon, nil, wgs84); err == nil {
+		t.Error("Expected an error for a missing projection")
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// This file reads and writes the protocol buffer wire format
+// used by Mapbox Vector Tiles
+
+// Protocol buffer wire types
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("Protocol buffer message is truncated")
+
+func appendUvarint(buffer []byte, value uint64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	return append(buffer, scratch[:binary.PutUvarint(scratch[:], value)]...)
+}
+
+func appendProtoKey(buffer []byte, field, wireType int) []byte {
+	return appendUvarint(buffer, uint64(field<<3|wireType))
+}
+
+func appendProtoVarint(buffer []byte, field int, value uint64) []byte {
+	return appendUvarint(appendProtoKey(buffer, field, protoVarint), value)
+}
+
+func appendProtoBytes(buffer []byte, field int, value []byte) []byte {
+	buffer = appendUvarint(appendProtoKey(buffer, field, protoBytes), uint64(len(value)))
+	return append(buffer, value...)
+}
+
+// appendProtoPacked appends a packed repeated varint field
+func appendProtoPacked(buffer []byte, field int, values []uint64) []byte {
+	var packed []byte
+	for _, value := range values {
+		packed = appendUvarint(packed, value)
+	}
+	return appendProtoBytes(buffer, field, packed)
+}
+
+// protoReader reads the fields of a protocol buffer message in turn
+type protoReader struct {
+	data []byte
+	pos  int
+}
+
+func (reader *protoReader) done() bool {
+	return reader.pos >= len(reader.data)
+}
+
+// key returns the field number and wire type of the next field
+func (reader *protoReader) key() (int, int, error) {
+	key, err := reader.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(key >> 3), int(key & 7), nil
+}
+
+func (reader *protoReader) varint() (uint64, error) {
+	value, length := binary.Uvarint(reader.data[reader.pos:])
+	if length <= 0 {
+		return 0, errProtoTruncated
+	}
+	reader.pos += length
+	return value, nil
+}
+
+func (reader *protoReader) bytes() ([]byte, error) {
+	length, err := reader.varint()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(reader.data)-reader.pos) {
+		return nil, errProtoTruncated
+	}
+	result := reader.data[reader.pos : reader.pos+int(length)]
+	reader.pos += int(length)
+	return result, nil
+}
+
+func (reader *protoReader) fixed32() (uint32, error) {
+	if len(reader.data)-reader.pos < 4 {
+		return 0, errProtoTruncated
+	}
+	result := binary.LittleEndian.Uint32(reader.data[reader.pos:])
+	reader.pos += 4
+	return result, nil
+}
+
+func (reader *protoReader) fixed64() (uint64, error) {
+	if len(reader.data)-reader.pos < 8 {
+		return 0, errProtoTruncated
+	}
+	result := binary.LittleEndian.Uint64(reader.data[reader.pos:])
+	reader.pos += 8
+	return result, nil
+}
+
+// uint64s appends the values of a repeated varint field,
+// which may or may not be packed
+func (reader *protoReader) uint64s(wireType int, values []uint64) ([]uint64, error) {
+	if wireType == protoVarint {
+		value, err := reader.varint()
+		return append(values, value), err
+	}
+	if wireType != protoBytes {
+		return values, fmt.Errorf("Unexpected wire type %v for a repeated varint", wireType)
+	}
+	packed, err := reader.bytes()
+	if err != nil {
+		return values, err
+	}
+	inner := protoReader{data: packed}
+	for !inner.done() {
+		value, err := inner.varint()
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// skip passes over the value of a field that is not needed
+func (reader *protoReader) skip(wireType int) error {
+	var err error
+	switch wireType {
+	case protoVarint:
+		_, err = reader.varint()
+	case protoFixed64:
+		_, err = reader.fixed64()
+	case protoBytes:
+		_, err = reader.bytes()
+	case protoFixed32:
+		_, err = reader.fixed32()
+	default:
+		err = fmt.Errorf("Unsupported wire type %v", wireType)
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	quadtreeCapacity = 16
+	quadtreeMaxDepth = 24
+)
+
+// PointIndex is a lightweight quadtree index of Features with Point geometries
+// in longitude/latitude, for large volumes of points where an RTree is heavier
+// than needed. A PointIndex is not safe for concurrent modification.
+type PointIndex struct {
+	root *quadNode
+	// entries holds the position each Feature was indexed at
+	entries map[*Feature]quadEntry
+}
+
+type quadEntry struct {
+	x, y    float64
+	feature *Feature
+}
+
+type quadNode struct {
+	rect     rtreeRect
+	depth    int
+	entries  []quadEntry
+	children []*quadNode
+}
+
+// NewPointIndex returns an empty PointIndex covering the globe
+func NewPointIndex() *PointIndex {
+	return &PointIndex{root: &quadNode{rect: rtreeRect{-180, -90, 180, 90}}, entries: make(map[*Feature]quadEntry)}
+}
+
+// Len returns the number of Features in the index
+func (index *PointIndex) Len() int {
+	return len(index.entries)
+}
+
+// Insert adds a Feature to the index, returning false if its geometry
+// is not a Point with a longitude and latitude or if it is already indexed
+func (index *PointIndex) Insert(feature *Feature) bool {
+	if _, ok := index.entries[feature]; ok {
+		return false
+	}
+	x, y, ok := indexablePoint(feature)
+	if !ok {
+		return false
+	}
+	entry := quadEntry{x: x, y: y, feature: feature}
+	index.root.insert(entry)
+	index.entries[feature] = entry
+	return true
+}
+
+// Remove removes a Feature from the index, returning false if it was not found.
+// Features are matched by identity, and are found at the position they
+// were inserted at even if they have moved since.
+func (index *PointIndex) Remove(feature *Feature) bool {
+	entry, ok := index.entries[feature]
+	if !ok {
+		return false
+	}
+	index.root.remove(entry)
+	delete(index.entries, feature)
+	return true
+}
+
+// SearchBbox returns the Features within the bounding box, including its edges.
+// Bounding boxes that cross the antimeridian are searched on both sides of it.
+func (index *PointIndex) SearchBbox(bbox BoundingBox) []*Feature {
+	var result []*Feature
+	for _, rect := range bboxRects(bbox) {
+		index.root.search(rect, func(entry quadEntry) {
+			result = append(result, entry.feature)
+		})
+	}
+	return result
+}
+
+// SearchRadius returns the Features within the great-circle distance in metres
+// of the point, nearest first
+func (index *PointIndex) SearchRadius(center *Point, radius float64) []*Feature {
+	if center == nil || len(center.Coordinates) < 2 || radius < 0 {
+		return nil
+	}
+	var (
+		result    []*Feature
+		distances = make(map[*Feature]float64)
+		x, y      = center.Coordinates[0], center.Coordinates[1]
+	)
+	for _, rect := range bboxRects(expandBbox(BoundingBox{x, y, x, y}, radius, Geodesic)) {
+		index.root.search(rect, func(entry quadEntry) {
+			if distance := haversineDistance(center.Coordinates, []float64{entry.x, entry.y}); distance <= radius {
+				distances[entry.feature] = distance
+				result = append(result, entry.feature)
+			}
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return distances[result[i]] < distances[result[j]]
+	})
+	return result
+}
+
+// indexablePoint returns the longitude and latitude of a Point Feature
+func indexablePoint(feature *Feature) (float64, float64, bool) {
+	if feature == nil {
+		return 0, 0, false
+	}
+	point, ok := feature.Geometry.(*Point)
+	if !ok || point == nil || len(point.Coordinates) < 2 {
+		return 0, 0, false
+	}
+	x, y := point.Coordinates[0], point.Coordinates[1]
+	if math.IsNaN(x) || math.IsNaN(y) || math.Abs(x) > 180 || math.Abs(y) > 90 {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+func (rect rtreeRect) containsPosition(x, y float64) bool {
+	return rect[0] <= x && x <= rect[2] && rect[1] <= y && y <= rect[3]
+}
+
+func (node *quadNode) insert(entry quadEntry) {
+	for node.children != nil {
+		node = node.children[node.quadrant(entry.x, entry.y)]
+	}
+	node.entries = append(node.entries, entry)
+	if len(node.entries) > quadtreeCapacity && node.depth < quadtreeMaxDepth {
+		node.split()
+	}
+}
+
+// quadrant returns which child of the node covers the position
+func (node *quadNode) quadrant(x, y float64) int {
+	midX, midY := (node.rect[0]+node.rect[2])/2, (node.rect[1]+node.rect[3])/2
+	result := 0
+	if x >= midX {
+		result++
+	}
+	if y >= midY {
+		result += 2
+	}
+	return result
+}
+
+func (node *quadNode) split() {
+	midX, midY := (node.rect[0]+node.rect[2])/2, (node.rect[1]+node.rect[3])/2
+	node.children = []*quadNode{
+		{rect: rtreeRect{node.rect[0], node.rect[1], midX, midY}, depth: node.depth + 1},
+		{rect: rtreeRect{midX, node.rect[1], node.rect[2], midY}, depth: node.depth + 1},
+		{rect: rtreeRect{node.rect[0], midY, midX, node.rect[3]}, depth: node.depth + 1},
+		{rect: rtreeRect{midX, midY, node.rect[2], node.rect[3]}, depth: node.depth + 1}}
+	entries := node.entries
+	node.entries = nil
+	for _, entry := range entries {
+		node.children[node.quadrant(entry.x, entry.y)].insert(entry)
+	}
+}
+
+// remove removes an entry, merging children back into the node
+// once they hold few enough entries
+func (node *quadNode) remove(entry quadEntry) bool {
+	if node.children == nil {
+		for inx, curr := range node.entries {
+			if curr.feature == entry.feature {
+				node.entries = append(node.entries[:inx], node.entries[inx+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	if !node.children[node.quadrant(entry.x, entry.y)].remove(entry) {
+		return false
+	}
+	var entries []quadEntry
+	for _, child := range node.children {
+		if child.children != nil {
+			return true
+		}
+		entries = append(entries, child.entries...)
+	}
+	if len(entries) <= quadtreeCapacity {
+		node.children = nil
+		node.entries = entries
+	}
+	return true
+}
+
+func (node *quadNode) search(rect rtreeRect, fn func(quadEntry)) {
+	if !node.rect.intersects(rect) {
+		return
+	}
+	for _, entry := range node.entries {
+		if rect.containsPosition(entry.x, entry.y) {
+			fn(entry)
+		}
+	}
+	for _, child := range node.children {
+		child.search(rect, fn)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomPointFeatures(count int) []*Feature {
+	random := rand.New(rand.NewSource(42))
+	var result []*Feature
+	for inx := 0; inx < count; inx++ {
+		position := []float64{random.Float64()*360 - 180, random.Float64()*180 - 90}
+		result = append(result, NewFeature(NewPoint(position), inx, nil))
+	}
+	return result
+}
+
+func TestPointIndexBbox(t *testing.T) {
+	features := randomPointFeatures(5000)
+	index := NewPointIndex()
+	for _, feature := range features {
+		if !index.Insert(feature) {
+			t.Fatalf("Failed to insert %v", feature.String())
+		}
+	}
+	if index.Len() != 5000 {
+		t.Errorf("Expected 5000 features, got %v", index.Len())
+	}
+	if index.Insert(NewFeature(NewLineString([][]float64{{0, 0}, {1, 1}}), nil, nil)) ||
+		index.Insert(NewFeature(NewPoint([]float64{200, 0}), nil, nil)) {
+		t.Error("Expected only longitude/latitude points to be indexed")
+	}
+	if index.Insert(features[0]) || index.Len() != 5000 {
+		t.Error("Expected a feature to be indexed only once")
+	}
+
+	for _, bbox := range []BoundingBox{{-10, -10, 30, 20}, {170, -40, -160, 40}, {-180, -90, 180, 90}} {
+		expected := 0
+		for _, feature := range features {
+			if bbox.ContainsPoint(feature.Geometry.(*Point)) {
+				expected++
+			}
+		}
+		if found := index.SearchBbox(bbox); len(found) != expected {
+			t.Errorf("Expected %v features in %v, got %v", expected, bbox, len(found))
+		}
+	}
+
+	for _, feature := range features[:4990] {
+		if !index.Remove(feature) {
+			t.Fatalf("Failed to remove %v", feature.ID)
+		}
+	}
+	if index.Remove(features[0]) || index.Len() != 10 {
+		t.Errorf("Expected 10 features, got %v", index.Len())
+	}
+	if found := index.SearchBbox(BoundingBox{-180, -90, 180, 90}); len(found) != 10 {
+		t.Errorf("Expected 10 features, got %v", len(found))
+	}
+
+	// A feature that moves after it is indexed is removed from where it was
+	moved := features[4990]
+	moved.Geometry = NewPoint([]float64{-179.5, 89.5})
+	if !index.Remove(moved) || index.Len() != 9 || len(index.SearchBbox(BoundingBox{-180, -90, 180, 90})) != 9 {
+		t.Error("Expected the moved feature to be removed")
+	}
+}
+
+func TestPointIndexRadius(t *testing.T) {
+	features := randomPointFeatures(5000)
+	features = append(features,
+		NewFeature(NewPoint([]float64{179.95, 0}), "east", nil),
+		NewFeature(NewPoint([]float64{-179.95, 0}), "west", nil))
+	index := NewPointIndex()
+	for _, feature := range features {
+		index.Insert(feature)
+	}
+
+	center := NewPoint([]float64{179.99, 0.01})
+	found := index.SearchRadius(center, 20000)
+	if len(found) < 2 || found[0].ID != "east" {
+		t.Fatalf("Expected both sides of the antimeridian, nearest first, got %v", found)
+	}
+
+	for _, center := range []*Point{NewPoint([]float64{10, 45}), NewPoint([]float64{-179, 60}), NewPoint([]float64{0, 89})} {
+		radius := 1000000.0
+		expected := 0
+		for _, feature := range features {
+			if haversineDistance(center.Coordinates, feature.Geometry.(*Point).Coordinates) <= radius {
+				expected++
+			}
+		}
+		found = index.SearchRadius(center, radius)
+		if len(found) != expected {
+			t.Errorf("Expected %v features near %v, got %v", expected, center.Coordinates, len(found))
+		}
+		for inx := 1; inx < len(found); inx++ {
+			if haversineDistance(center.Coordinates, found[inx-1].Geometry.(*Point).Coordinates) >
+				haversineDistance(center.Coordinates, found[inx].Geometry.(*Point).Coordinates) {
+				t.Errorf("Expected results nearest first")
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,489 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+const (
+	rtreeMaxEntries = 9
+	rtreeMinEntries = 4
+)
+
+// RTree is an in-memory spatial index of Features by their bounding boxes.
+// Bounding boxes that cross the antimeridian are indexed as two rectangles,
+// one either side of it. Features without geometry are not indexed.
+// An RTree is not safe for concurrent modification.
+type RTree struct {
+	root *rtreeNode
+	// rects holds the rectangles each Feature was indexed by,
+	// which may no longer match the Feature if it has changed since
+	rects map[*Feature][]rtreeRect
+}
+
+// rtreeRect is a two dimensional box as [minX, minY, maxX, maxY]
+type rtreeRect [4]float64
+
+type rtreeEntry struct {
+	rect    rtreeRect
+	feature *Feature
+}
+
+type rtreeNode struct {
+	rect     rtreeRect
+	leaf     bool
+	children []*rtreeNode
+	entries  []rtreeEntry
+}
+
+// NewRTree returns an RTree of the features in the collection,
+// bulk loaded with the Sort-Tile-Recursive algorithm.
+// The collection may be nil for an empty index.
+func NewRTree(fc *FeatureCollection) *RTree {
+	var entries []rtreeEntry
+	rects := make(map[*Feature][]rtreeRect)
+	if fc != nil {
+		for _, feature := range fc.Features {
+			if _, ok := rects[feature]; ok {
+				continue
+			}
+			featureEntries := featureRects(feature)
+			for _, entry := range featureEntries {
+				rects[feature] = append(rects[feature], entry.rect)
+			}
+			entries = append(entries, featureEntries...)
+		}
+	}
+	return &RTree{root: strLoad(entries), rects: rects}
+}
+
+// Len returns the number of Features in the index
+func (tree *RTree) Len() int {
+	return len(tree.rects)
+}
+
+// Insert adds a Feature to the index.
+// Inserting a Feature that is already indexed updates its bounding box.
+func (tree *RTree) Insert(feature *Feature) {
+	tree.Remove(feature)
+	for _, entry := range featureRects(feature) {
+		tree.insert(entry)
+		tree.rects[feature] = append(tree.rects[feature], entry.rect)
+	}
+}
+
+// Remove removes a Feature from the index,
+// returning false if it was not found.
+// Features are matched by identity, not by value, and are found
+// by the bounding box they were indexed with even if they have changed since.
+func (tree *RTree) Remove(feature *Feature) bool {
+	rects, removed := tree.rects[feature]
+	if removed {
+		for _, rect := range rects {
+			tree.root.remove(rtreeEntry{rect: rect, feature: feature})
+		}
+		delete(tree.rects, feature)
+		switch {
+		case tree.root.leaf:
+		case len(tree.root.children) == 0:
+			tree.root = &rtreeNode{leaf: true, rect: emptyRect()}
+		case len(tree.root.children) == 1:
+			tree.root = tree.root.children[0]
+		}
+	}
+	return removed
+}
+
+// Search returns the Features whose bounding boxes intersect the bounding box
+// provided, including those that only touch it
+func (tree *RTree) Search(bbox BoundingBox) []*Feature {
+	var result []*Feature
+	seen := make(map[*Feature]bool)
+	for _, rect := range bboxRects(bbox) {
+		tree.root.search(rect, func(entry rtreeEntry) {
+			if !seen[entry.feature] {
+				seen[entry.feature] = true
+				result = append(result, entry.feature)
+			}
+		})
+	}
+	return result
+}
+
+// SearchPoint returns the Features whose bounding boxes contain the point
+func (tree *RTree) SearchPoint(point *Point) []*Feature {
+	if point == nil || len(point.Coordinates) < 2 {
+		return nil
+	}
+	x, y := point.Coordinates[0], point.Coordinates[1]
+	return tree.Search(BoundingBox{x, y, x, y})
+}
+
+// Nearest returns up to k Features nearest the point, nearest first.
+// Distances are planar, in coordinate units, and measured to the
+// Features' geometries rather than their bounding boxes.
+func (tree *RTree) Nearest(point *Point, k int) []*Feature {
+	var result []*Feature
+	if point == nil || len(point.Coordinates) < 2 || k <= 0 {
+		return result
+	}
+	p := point.Coordinates
+	seen := make(map[*Feature]bool)
+	queue := &rtreeQueue{}
+	heap.Push(queue, rtreeQueueItem{node: tree.root, distance: tree.root.rect.distance(p)})
+	for queue.Len() > 0 && len(result) < k {
+		item := heap.Pop(queue).(rtreeQueueItem)
+		switch {
+		case item.feature != nil:
+			result = append(result, item.feature)
+		case item.node.leaf:
+			for _, entry := range item.node.entries {
+				if seen[entry.feature] {
+					continue
+				}
+				seen[entry.feature] = true
+				distance, _, _ := Distance(point, entry.feature, Planar)
+				if math.IsNaN(distance) {
+					distance = entry.rect.distance(p)
+				}
+				heap.Push(queue, rtreeQueueItem{feature: entry.feature, distance: distance})
+			}
+		default:
+			for _, child := range item.node.children {
+				heap.Push(queue, rtreeQueueItem{node: child, distance: child.rect.distance(p)})
+			}
+		}
+	}
+	return result
+}
+
+// featureRects returns the index entries for a Feature
+func featureRects(feature *Feature) []rtreeEntry {
+	if feature == nil || feature.Geometry == nil {
+		return nil
+	}
+	var result []rtreeEntry
+	for _, rect := range bboxRects(feature.resolvedBbox()) {
+		result = append(result, rtreeEntry{rect: rect, feature: feature})
+	}
+	return result
+}
+
+// bboxRects returns the rectangles covered by a bounding box,
+// splitting it in two if it crosses the antimeridian
+func bboxRects(bbox BoundingBox) []rtreeRect {
+	if bbox.Valid() != nil || len(bbox) == 0 {
+		return nil
+	}
+	half := len(bbox) / 2
+	west, south, east, north := bbox[0], bbox[1], bbox[half], bbox[half+1]
+	if bbox.Antimeridian() {
+		return []rtreeRect{{west, south, 180, north}, {-180, south, east, north}}
+	}
+	return []rtreeRect{{west, south, east, north}}
+}
+
+func emptyRect() rtreeRect {
+	return rtreeRect{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+}
+
+func (rect rtreeRect) extend(other rtreeRect) rtreeRect {
+	return rtreeRect{
+		math.Min(rect[0], other[0]), math.Min(rect[1], other[1]),
+		math.Max(rect[2], other[2]), math.Max(rect[3], other[3])}
+}
+
+func (rect rtreeRect) area() float64 {
+	return (rect[2] - rect[0]) * (rect[3] - rect[1])
+}
+
+func (rect rtreeRect) intersects(other rtreeRect) bool {
+	return rect[0] <= other[2] && other[0] <= rect[2] && rect[1] <= other[3] && other[1] <= rect[3]
+}
+
+func (rect rtreeRect) contains(other rtreeRect) bool {
+	return rect[0] <= other[0] && other[2] <= rect[2] && rect[1] <= other[1] && other[3] <= rect[3]
+}
+
+// distance returns the planar distance from a position to the rectangle
+func (rect rtreeRect) distance(p []float64) float64 {
+	dx := math.Max(0, math.Max(rect[0]-p[0], p[0]-rect[2]))
+	dy := math.Max(0, math.Max(rect[1]-p[1], p[1]-rect[3]))
+	return math.Hypot(dx, dy)
+}
+
+func (node *rtreeNode) size() int {
+	if node.leaf {
+		return len(node.entries)
+	}
+	return len(node.children)
+}
+
+// recalculate sets the rectangle of the node from its contents
+func (node *rtreeNode) recalculate() {
+	node.rect = emptyRect()
+	for _, entry := range node.entries {
+		node.rect = node.rect.extend(entry.rect)
+	}
+	for _, child := range node.children {
+		node.rect = node.rect.extend(child.rect)
+	}
+}
+
+func (node *rtreeNode) search(rect rtreeRect, fn func(rtreeEntry)) {
+	if !node.rect.intersects(rect) {
+		return
+	}
+	for _, entry := range node.entries {
+		if entry.rect.intersects(rect) {
+			fn(entry)
+		}
+	}
+	for _, child := range node.children {
+		child.search(rect, fn)
+	}
+}
+
+// strLoad returns the root of a tree packed with the entries
+// using the Sort-Tile-Recursive algorithm
+func strLoad(entries []rtreeEntry) *rtreeNode {
+	if len(entries) == 0 {
+		return &rtreeNode{leaf: true, rect: emptyRect()}
+	}
+	var nodes []*rtreeNode
+	strTiles(len(entries), func(inx int) rtreeRect { return entries[inx].rect },
+		func(i, j int) { entries[i], entries[j] = entries[j], entries[i] },
+		func(start, end int) {
+			node := &rtreeNode{leaf: true, entries: append([]rtreeEntry{}, entries[start:end]...)}
+			node.recalculate()
+			nodes = append(nodes, node)
+		})
+	for len(nodes) > 1 {
+		level := nodes
+		nodes = nil
+		strTiles(len(level), func(inx int) rtreeRect { return level[inx].rect },
+			func(i, j int) { level[i], level[j] = level[j], level[i] },
+			func(start, end int) {
+				node := &rtreeNode{children: append([]*rtreeNode{}, level[start:end]...)}
+				node.recalculate()
+				nodes = append(nodes, node)
+			})
+	}
+	return nodes[0]
+}
+
+// strTiles sorts items into vertical slices by the centres of their rectangles,
+// sorts each slice by centre, and calls pack for each run of up to
+// rtreeMaxEntries items in turn
+func strTiles(count int, rect func(int) rtreeRect, swap func(int, int), pack func(int, int)) {
+	center := func(inx, axis int) float64 {
+		r := rect(inx)
+		return r[axis] + r[axis+2]
+	}
+	sortRange := func(start, end, axis int) {
+		sort.Sort(rtreeSorter{start: start, length: end - start, swap: swap,
+			less: func(i, j int) bool { return center(i, axis) < center(j, axis) }})
+	}
+	nodeCount := int(math.Ceil(float64(count) / rtreeMaxEntries))
+	sliceSize := rtreeMaxEntries * int(math.Ceil(math.Sqrt(float64(nodeCount))))
+	sortRange(0, count, 0)
+	for sliceStart := 0; sliceStart < count; sliceStart += sliceSize {
+		sliceEnd := int(math.Min(float64(sliceStart+sliceSize), float64(count)))
+		sortRange(sliceStart, sliceEnd, 1)
+		for start := sliceStart; start < sliceEnd; start += rtreeMaxEntries {
+			pack(start, int(math.Min(float64(start+rtreeMaxEntries), float64(sliceEnd))))
+		}
+	}
+}
+
+// rtreeSorter sorts a range of items that are accessed by index
+type rtreeSorter struct {
+	start, length int
+	less          func(int, int) bool
+	swap          func(int, int)
+}
+
+func (sorter rtreeSorter) Len() int           { return sorter.length }
+func (sorter rtreeSorter) Less(i, j int) bool { return sorter.less(sorter.start+i, sorter.start+j) }
+func (sorter rtreeSorter) Swap(i, j int)      { sorter.swap(sorter.start+i, sorter.start+j) }
+
+// insert adds an entry at a leaf, splitting nodes that overflow
+func (tree *RTree) insert(entry rtreeEntry) {
+	path := []*rtreeNode{tree.root}
+	node := tree.root
+	for !node.leaf {
+		node = chooseSubtree(node, entry.rect)
+		path = append(path, node)
+	}
+	node.entries = append(node.entries, entry)
+	for inx := len(path) - 1; inx >= 0; inx-- {
+		node = path[inx]
+		node.rect = node.rect.extend(entry.rect)
+		if node.size() <= rtreeMaxEntries {
+			continue
+		}
+		sibling := node.split()
+		if inx == 0 {
+			tree.root = &rtreeNode{children: []*rtreeNode{node, sibling}}
+			tree.root.recalculate()
+		} else {
+			parent := path[inx-1]
+			parent.children = append(parent.children, sibling)
+		}
+	}
+}
+
+// chooseSubtree returns the child needing the least enlargement to include the rectangle
+func chooseSubtree(node *rtreeNode, rect rtreeRect) *rtreeNode {
+	var (
+		best            *rtreeNode
+		bestEnlargement = math.Inf(1)
+		bestArea        = math.Inf(1)
+	)
+	for _, child := range node.children {
+		area := child.rect.area()
+		enlargement := child.rect.extend(rect).area() - area
+		if enlargement < bestEnlargement || (enlargement == bestEnlargement && area < bestArea) {
+			best, bestEnlargement, bestArea = child, enlargement, area
+		}
+	}
+	return best
+}
+
+// split moves about half of the contents of an overflowing node into a new sibling,
+// using Guttman's quadratic split
+func (node *rtreeNode) split() *rtreeNode {
+	count := node.size()
+	rects := make([]rtreeRect, count)
+	for inx := range rects {
+		if node.leaf {
+			rects[inx] = node.entries[inx].rect
+		} else {
+			rects[inx] = node.children[inx].rect
+		}
+	}
+
+	// Pick the two seeds that would waste the most area together
+	seedA, seedB, worst := 0, 1, math.Inf(-1)
+	for i := 0; i < count; i++ {
+		for j := i + 1; j < count; j++ {
+			if waste := rects[i].extend(rects[j]).area() - rects[i].area() - rects[j].area(); waste > worst {
+				seedA, seedB, worst = i, j, waste
+			}
+		}
+	}
+	groups := [2][]int{{seedA}, {seedB}}
+	groupRects := [2]rtreeRect{rects[seedA], rects[seedB]}
+	remaining := count - 2
+	for inx := 0; inx < count; inx++ {
+		if inx == seedA || inx == seedB {
+			continue
+		}
+		var group int
+		switch {
+		// Keep both groups at least minimally full
+		case len(groups[0])+remaining <= rtreeMinEntries:
+			group = 0
+		case len(groups[1])+remaining <= rtreeMinEntries:
+			group = 1
+		default:
+			growthA := groupRects[0].extend(rects[inx]).area() - groupRects[0].area()
+			growthB := groupRects[1].extend(rects[inx]).area() - groupRects[1].area()
+			if growthB < growthA || (growthB == growthA && len(groups[1]) < len(groups[0])) {
+				group = 1
+			}
+		}
+		groups[group] = append(groups[group], inx)
+		groupRects[group] = groupRects[group].extend(rects[inx])
+		remaining--
+	}
+
+	sibling := &rtreeNode{leaf: node.leaf}
+	if node.leaf {
+		entries := node.entries
+		node.entries = nil
+		for _, inx := range groups[0] {
+			node.entries = append(node.entries, entries[inx])
+		}
+		for _, inx := range groups[1] {
+			sibling.entries = append(sibling.entries, entries[inx])
+		}
+	} else {
+		children := node.children
+		node.children = nil
+		for _, inx := range groups[0] {
+			node.children = append(node.children, children[inx])
+		}
+		for _, inx := range groups[1] {
+			sibling.children = append(sibling.children, children[inx])
+		}
+	}
+	node.recalculate()
+	sibling.recalculate()
+	return sibling
+}
+
+// remove removes an entry from the subtree, dropping nodes that become empty
+// and shrinking the rectangles of their ancestors
+func (node *rtreeNode) remove(entry rtreeEntry) bool {
+	if !node.rect.contains(entry.rect) {
+		return false
+	}
+	if node.leaf {
+		for inx, curr := range node.entries {
+			if curr.feature == entry.feature && curr.rect == entry.rect {
+				node.entries = append(node.entries[:inx], node.entries[inx+1:]...)
+				node.recalculate()
+				return true
+			}
+		}
+		return false
+	}
+	for inx, child := range node.children {
+		if child.remove(entry) {
+			if child.size() == 0 {
+				node.children = append(node.children[:inx], node.children[inx+1:]...)
+			}
+			node.recalculate()
+			return true
+		}
+	}
+	return false
+}
+
+type rtreeQueueItem struct {
+	node     *rtreeNode
+	feature  *Feature
+	distance float64
+}
+
+// rtreeQueue is a priority queue of nodes and Features, nearest first
+type rtreeQueue []rtreeQueueItem
+
+func (queue rtreeQueue) Len() int            { return len(queue) }
+func (queue rtreeQueue) Less(i, j int) bool  { return queue[i].distance < queue[j].distance }
+func (queue rtreeQueue) Swap(i, j int)       { queue[i], queue[j] = queue[j], queue[i] }
+func (queue *rtreeQueue) Push(x interface{}) { *queue = append(*queue, x.(rtreeQueueItem)) }
+func (queue *rtreeQueue) Pop() interface{} {
+	old := *queue
+	item := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"sort"
+	"testing"
+)
+
+func gridFeatures(size int) []*Feature {
+	var result []*Feature
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			result = append(result, NewFeature(NewPoint([]float64{float64(x), float64(y)}), x*size+y, nil))
+		}
+	}
+	return result
+}
+
+// checkRTree verifies that every node's rectangle covers its contents
+// and returns the number of entries
+func checkRTree(t *testing.T, node *rtreeNode) int {
+	count := len(node.entries)
+	for _, entry := range node.entries {
+		if !node.rect.contains(entry.rect) {
+			t.Errorf("Node %v does not contain entry %v", node.rect, entry.rect)
+		}
+	}
+	for _, child := range node.children {
+		if !node.rect.contains(child.rect) {
+			t.Errorf("Node %v does not contain child %v", node.rect, child.rect)
+		}
+		count += checkRTree(t, child)
+	}
+	return count
+}
+
+func sortedIDs(features []*Feature) []int {
+	var result []int
+	for _, feature := range features {
+		result = append(result, feature.ID.(int))
+	}
+	sort.Ints(result)
+	return result
+}
+
+func TestRTreeSearch(t *testing.T) {
+	features := gridFeatures(20)
+	tree := NewRTree(NewFeatureCollection(features))
+	if tree.Len() != 400 || checkRTree(t, tree.root) != 400 {
+		t.Fatalf("Expected 400 features, got %v", tree.Len())
+	}
+
+	// Bounds are inclusive
+	ids := sortedIDs(tree.Search(BoundingBox{2, 3, 4, 4}))
+	expected := []int{43, 44, 63, 64, 83, 84}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+	for inx := range ids {
+		if ids[inx] != expected[inx] {
+			t.Errorf("Expected %v, got %v", expected, ids)
+		}
+	}
+	if found := tree.SearchPoint(NewPoint([]float64{5, 5})); len(found) != 1 || found[0].ID != 105 {
+		t.Errorf("Expected feature 105, got %v", found)
+	}
+	if found := tree.Search(BoundingBox{50, 50, 60, 60}); len(found) != 0 {
+		t.Errorf("Expected nothing, got %v", found)
+	}
+}
+
+func TestRTreeInsertRemove(t *testing.T) {
+	tree := NewRTree(nil)
+	features := gridFeatures(15)
+	for _, feature := range features {
+		tree.Insert(feature)
+	}
+	if tree.Len() != 225 || checkRTree(t, tree.root) != 225 {
+		t.Fatalf("Expected 225 features, got %v", tree.Len())
+	}
+	if found := tree.Search(BoundingBox{0, 0, 14, 14}); len(found) != 225 {
+		t.Errorf("Expected 225 features, got %v", len(found))
+	}
+
+	for _, feature := range features[:200] {
+		if !tree.Remove(feature) {
+			t.Fatalf("Failed to remove %v", feature.ID)
+		}
+	}
+	if tree.Remove(features[0]) {
+		t.Error("Removed a feature twice")
+	}
+	if tree.Len() != 25 || checkRTree(t, tree.root) != 25 {
+		t.Errorf("Expected 25 features, got %v", tree.Len())
+	}
+	if found := tree.Search(BoundingBox{0, 0, 14, 14}); len(found) != 25 {
+		t.Errorf("Expected 25 features, got %v", len(found))
+	}
+	for _, feature := range features[200:] {
+		tree.Remove(feature)
+	}
+	if tree.Len() != 0 || len(tree.Search(BoundingBox{-180, -90, 180, 90})) != 0 {
+		t.Error("Expected an empty index")
+	}
+	tree.Insert(features[0])
+	if found := tree.SearchPoint(NewPoint([]float64{0, 0})); len(found) != 1 {
+		t.Errorf("Expected to find the reinserted feature, got %v", found)
+	}
+	tree.Insert(NewFeature(nil, nil, nil))
+	if tree.Len() != 1 {
+		t.Error("Expected a feature without geometry to be ignored")
+	}
+	tree.Insert(features[0])
+	if tree.Len() != 1 || checkRTree(t, tree.root) != 1 {
+		t.Error("Expected a feature inserted twice to be indexed once")
+	}
+
+	// A feature that moves after it is indexed is still removed by its old bounding box
+	moved := NewFeature(NewPoint([]float64{50, 50}), "moved", nil)
+	tree.Insert(moved)
+	moved.Geometry = NewPoint([]float64{-50, -50})
+	if !tree.Remove(moved) {
+		t.Fatal("Failed to remove a feature that moved")
+	}
+	if tree.Len() != 1 || checkRTree(t, tree.root) != 1 || len(tree.SearchPoint(NewPoint([]float64{50, 50}))) != 0 {
+		t.Error("Expected the moved feature to be removed from the index")
+	}
+}
+
+func TestRTreeAntimeridian(t *testing.T) {
+	crossing := NewFeature(NewLineString([][]float64{{170, 0}, {-170, 10}}), "crossing", nil)
+	crossing.Bbox = BoundingBox{170, 0, -170, 10}
+	local := NewFeature(NewPoint([]float64{0, 5}), "local", nil)
+	tree := NewRTree(NewFeatureCollection([]*Feature{crossing, local}))
+
+	for _, x := range []float64{175, -175} {
+		if found := tree.SearchPoint(NewPoint([]float64{x, 5})); len(found) != 1 || found[0] != crossing {
+			t.Errorf("Expected the crossing feature at %v, got %v", x, found)
+		}
+	}
+	if found := tree.Search(BoundingBox{179, 0, -179, 10}); len(found) != 1 {
+		t.Errorf("Expected one feature, got %v", found)
+	}
+	if found := tree.Search(BoundingBox{-180, -90, 180, 90}); len(found) != 2 {
+		t.Errorf("Expected each feature once, got %v", found)
+	}
+	if !tree.Remove(crossing) || tree.Len() != 1 || len(tree.SearchPoint(NewPoint([]float64{175, 5}))) != 0 {
+		t.Error("Expected both parts of the crossing feature to be removed")
+	}
+}
+
+func TestRTreeNearest(t *testing.T) {
+	features := gridFeatures(10)
+	features = append(features, NewFeature(NewLineString([][]float64{{20, -5}, {20, 15}}), 1000, nil))
+	tree := NewRTree(NewFeatureCollection(features))
+
+	point := NewPoint([]float64{3.2, 4.4})
+	nearest := tree.Nearest(point, 5)
+	if len(nearest) != 5 || nearest[0].ID != 34 {
+		t.Fatalf("Expected feature 34 first, got %v", nearest)
+	}
+	previous := 0.0
+	for _, feature := range nearest {
+		distance, _, _ := Distance(point, feature, Planar)
+		if distance < previous {
+			t.Errorf("Expected results nearest first, got %v after %v", distance, previous)
+		}
+		previous = distance
+	}
+	// Nothing left out is nearer than the last result
+	for _, feature := range features {
+		if distance, _, _ := Distance(point, feature, Planar); distance < previous-1e-12 {
+			found := false
+			for _, curr := range nearest {
+				found = found || curr == feature
+			}
+			if !found {
+				t.Errorf("Feature %v at %v was missed", feature.ID, distance)
+			}
+		}
+	}
+
+	// Lines are measured to the line, not the bbox centre
+	nearest = tree.Nearest(NewPoint([]float64{19, 12}), 1)
+	if len(nearest) != 1 || nearest[0].ID != 1000 {
+		t.Errorf("Expected the line, got %v", nearest)
+	}
+	if len(tree.Nearest(point, 1000)) != 101 {
+		t.Error("Expected every feature")
+	}
+}
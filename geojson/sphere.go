@@ -0,0 +1,91 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "math"
+
+// EarthRadius is the mean radius of the WGS84 ellipsoid in metres,
+// used for great-circle calculations
+const EarthRadius = 6371008.8
+
+// DistanceMode selects how distances between positions are measured
+type DistanceMode int
+
+const (
+	// Planar measures straight-line distances in coordinate units
+	Planar DistanceMode = iota
+	// Geodesic measures great-circle distances in metres
+	// between longitude/latitude positions
+	Geodesic
+)
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func toDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// positionDistance returns the distance between two positions,
+// considering only the first two ordinates
+func positionDistance(a, b []float64, mode DistanceMode) float64 {
+	if mode == Geodesic {
+		return haversineDistance(a, b)
+	}
+	return math.Hypot(b[0]-a[0], b[1]-a[1])
+}
+
+// haversineDistance returns the great-circle distance in metres
+// between two longitude/latitude positions
+func haversineDistance(a, b []float64) float64 {
+	return EarthRadius * angularDistance(a, b)
+}
+
+// angularDistance returns the great-circle distance in radians
+// between two longitude/latitude positions
+func angularDistance(a, b []float64) float64 {
+	lat1, lat2 := toRadians(a[1]), toRadians(b[1])
+	dLat := lat2 - lat1
+	dLon := toRadians(b[0] - a[0])
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// interpolate returns the position at the given fraction of the way between
+// two positions, along a straight line or a great circle.
+// Ordinates beyond the first two are interpolated linearly.
+func interpolate(a, b []float64, fraction float64, mode DistanceMode) []float64 {
+	result := interpolatePosition(a, b, fraction)
+	if mode != Geodesic {
+		return result
+	}
+	delta := angularDistance(a, b)
+	if delta == 0 {
+		return result
+	}
+	lat1, lon1 := toRadians(a[1]), toRadians(a[0])
+	lat2, lon2 := toRadians(b[1]), toRadians(b[0])
+	sinA := math.Sin((1-fraction)*delta) / math.Sin(delta)
+	sinB := math.Sin(fraction*delta) / math.Sin(delta)
+	x := sinA*math.Cos(lat1)*math.Cos(lon1) + sinB*math.Cos(lat2)*math.Cos(lon2)
+	y := sinA*math.Cos(lat1)*math.Sin(lon1) + sinB*math.Cos(lat2)*math.Sin(lon2)
+	z := sinA*math.Sin(lat1) + sinB*math.Sin(lat2)
+	result[0] = toDegrees(math.Atan2(y, x))
+	result[1] = toDegrees(math.Atan2(z, math.Sqrt(x*x+y*y)))
+	return result
+}
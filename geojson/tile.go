@@ -0,0 +1,269 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MaxTileZoom is the deepest zoom level supported by the tile functions.
+// Zoom levels outside 0 to MaxTileZoom are clamped to that range.
+const MaxTileZoom = 30
+
+// Tile identifies an XYZ ("slippy map") Web Mercator tile,
+// numbered from the northwest corner of the map
+type Tile struct {
+	X, Y, Z int
+}
+
+// String returns the tile as "z/x/y"
+func (tile Tile) String() string {
+	return fmt.Sprintf("%v/%v/%v", tile.Z, tile.X, tile.Y)
+}
+
+// TileForPoint returns the tile at the zoom level containing the position.
+// Latitudes beyond MaxMercatorLatitude fall in the first or last row.
+func TileForPoint(longitude, latitude float64, zoom int) Tile {
+	zoom = clampZoom(zoom)
+	x, y := tileFraction(longitude, latitude, zoom)
+	n := 1 << uint(zoom)
+	return Tile{X: clampTile(int(math.Floor(x)), n), Y: clampTile(int(math.Floor(y)), n), Z: zoom}
+}
+
+// BoundingBox returns the longitude/latitude bounds of the tile
+func (tile Tile) BoundingBox() BoundingBox {
+	n := float64(int(1) << uint(tile.Z))
+	latitude := func(y int) float64 {
+		return toDegrees(math.Atan(math.Sinh(math.Pi * (1 - 2*float64(y)/n))))
+	}
+	return BoundingBox{
+		float64(tile.X)/n*360 - 180, latitude(tile.Y + 1),
+		float64(tile.X+1)/n*360 - 180, latitude(tile.Y)}
+}
+
+// TilesCoveringBbox returns the tiles at the zoom level that the bounding box
+// overlaps, sorted by column and then row. Bounding boxes that cross the
+// antimeridian are covered on both sides of it. Tiles that only touch the
+// east or south edge of the bounding box are not included.
+func TilesCoveringBbox(bbox BoundingBox, zoom int) []Tile {
+	zoom = clampZoom(zoom)
+	n := 1 << uint(zoom)
+	result := []Tile{}
+	for _, rect := range bboxRects(bbox) {
+		minX, minY := tileFraction(rect[0], rect[3], zoom)
+		maxX, maxY := tileFraction(rect[2], rect[1], zoom)
+		for x := clampTile(int(math.Floor(minX)), n); x <= clampTile(lastTile(minX, maxX), n); x++ {
+			for y := clampTile(int(math.Floor(minY)), n); y <= clampTile(lastTile(minY, maxY), n); y++ {
+				result = append(result, Tile{X: x, Y: y, Z: zoom})
+			}
+		}
+	}
+	sortTiles(result)
+	return result
+}
+
+// TilesCoveringGeometry returns the tiles at the zoom level that the geometry
+// actually intersects, sorted by column and then row, rather than every tile
+// overlapping its bounding box. The geometry is split at the antimeridian
+// first, and tiles that it only touches along an edge or at a corner are not
+// included. Edges are straight lines in longitude/latitude, and parts beyond
+// MaxMercatorLatitude are not covered.
+func TilesCoveringGeometry(geometry interface{}, zoom int) []Tile {
+	zoom = clampZoom(zoom)
+	result := []Tile{}
+	if decompose(geometry).empty() {
+		return result
+	}
+	geometry = SplitAntimeridian(geometry)
+	var cover func(tile Tile)
+	cover = func(tile Tile) {
+		tileGeometry := tile.BoundingBox().Geometry()
+		if !Intersects(geometry, tileGeometry) {
+			return
+		}
+		if tile.Z == zoom {
+			if tile.overlaps(geometry) {
+				result = append(result, tile)
+			}
+			return
+		}
+		if Contains(geometry, tileGeometry) {
+			// Every descendant is covered, so there is nothing more to test
+			scale := 1 << uint(zoom-tile.Z)
+			for x := tile.X * scale; x < (tile.X+1)*scale; x++ {
+				for y := tile.Y * scale; y < (tile.Y+1)*scale; y++ {
+					result = append(result, Tile{X: x, Y: y, Z: zoom})
+				}
+			}
+			return
+		}
+		for _, child := range tile.children() {
+			cover(child)
+		}
+	}
+	cover(Tile{})
+	sortTiles(result)
+	return result
+}
+
+// overlaps returns true if the geometry shares more than part of the tile's
+// boundary with it. Points, and lines that run along the boundary, belong to
+// the tile that TileForPoint returns for them.
+func (tile Tile) overlaps(geometry interface{}) bool {
+	bbox := tile.BoundingBox()
+	rect := rtreeRect{bbox[0], bbox[1], bbox[2], bbox[3]}
+	switch typed := geometry.(type) {
+	case *Point:
+		return typed != nil && tile.owns(typed.Coordinates)
+	case *MultiPoint:
+		for _, position := range typed.Coordinates {
+			if tile.owns(position) {
+				return true
+			}
+		}
+	case *LineString:
+		return tile.overlapsLines(typed.Coordinates, rect)
+	case *MultiLineString:
+		for _, line := range typed.Coordinates {
+			if tile.overlapsLines(line, rect) {
+				return true
+			}
+		}
+	case *Polygon:
+		return tile.overlapsPolygon(typed.Coordinates, rect)
+	case *MultiPolygon:
+		for _, polygon := range typed.Coordinates {
+			if tile.overlapsPolygon(polygon, rect) {
+				return true
+			}
+		}
+	case *GeometryCollection:
+		for _, curr := range typed.Geometries {
+			if tile.overlaps(curr) {
+				return true
+			}
+		}
+	case *Feature:
+		return typed != nil && tile.overlaps(typed.Geometry)
+	}
+	return false
+}
+
+// owns returns true if the position lies in the tile
+func (tile Tile) owns(position []float64) bool {
+	return len(position) >= 2 && TileForPoint(position[0], position[1], tile.Z) == tile
+}
+
+// overlapsLines returns true if part of the line lies inside the tile,
+// or along the part of its boundary that the tile owns
+func (tile Tile) overlapsLines(line [][]float64, rect rtreeRect) bool {
+	if len(line) == 1 {
+		return tile.owns(line[0])
+	}
+	for _, piece := range clipLine(line, rect) {
+		for inx := 1; inx < len(piece); inx++ {
+			middle := interpolatePosition(piece[inx-1], piece[inx], 0.5)
+			if (rect[0] < middle[0] && middle[0] < rect[2] && rect[1] < middle[1] && middle[1] < rect[3]) || tile.owns(middle) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlapsPolygon returns true if the polygon covers some of the tile's area.
+// A polygon without area is treated as the line of its exterior ring.
+func (tile Tile) overlapsPolygon(polygon [][][]float64, rect rtreeRect) bool {
+	if len(polygon) == 0 {
+		return false
+	}
+	if ringArea(polygon[0]) == 0 {
+		return tile.overlapsLines(polygon[0], rect)
+	}
+	for _, clipped := range clipPolygon(polygon, rect) {
+		area := math.Abs(ringArea(clipped[0]))
+		for _, hole := range clipped[1:] {
+			area -= math.Abs(ringArea(hole))
+		}
+		// Allow for rounding where a hole covers the whole tile
+		if area > rect.area()*1e-9 {
+			return true
+		}
+	}
+	return false
+}
+
+// children returns the four tiles at the next zoom level within the tile
+func (tile Tile) children() []Tile {
+	x, y, z := 2*tile.X, 2*tile.Y, tile.Z+1
+	return []Tile{{x, y, z}, {x + 1, y, z}, {x, y + 1, z}, {x + 1, y + 1, z}}
+}
+
+// parent returns the tile at the previous zoom level containing the tile
+func (tile Tile) parent() Tile {
+	return Tile{tile.X / 2, tile.Y / 2, tile.Z - 1}
+}
+
+// tileFraction returns the fractional tile column and row of a position
+func tileFraction(longitude, latitude float64, zoom int) (float64, float64) {
+	n := float64(int(1) << uint(zoom))
+	latitude = math.Max(-MaxMercatorLatitude, math.Min(MaxMercatorLatitude, latitude))
+	sin := math.Sin(toRadians(latitude))
+	x := (longitude + 180) / 360 * n
+	y := (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * n
+	return x, y
+}
+
+// lastTile returns the last tile index overlapped by a range of fractional indexes,
+// excluding a tile that the range only reaches the edge of
+func lastTile(start, end float64) int {
+	if end > start && end == math.Floor(end) {
+		return int(end) - 1
+	}
+	return int(math.Floor(end))
+}
+
+func clampTile(index, n int) int {
+	if index < 0 {
+		return 0
+	}
+	if index >= n {
+		return n - 1
+	}
+	return index
+}
+
+func clampZoom(zoom int) int {
+	if zoom < 0 {
+		return 0
+	}
+	if zoom > MaxTileZoom {
+		return MaxTileZoom
+	}
+	return zoom
+}
+
+func sortTiles(tiles []Tile) {
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].X != tiles[j].X {
+			return tiles[i].X < tiles[j].X
+		}
+		return tiles[i].Y < tiles[j].Y
+	})
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+func tilesString(tiles []Tile) string {
+	result := ""
+	for _, tile := range tiles {
+		result += tile.String() + " "
+	}
+	return result
+}
+
+func TestTileForPoint(t *testing.T) {
+	if tile := TileForPoint(-122.4194, 37.7749, 12); tile != (Tile{X: 655, Y: 1583, Z: 12}) {
+		t.Errorf("Expected 12/655/1583, got %v", tile)
+	}
+	if tile := TileForPoint(180, -90, 2); tile != (Tile{X: 3, Y: 3, Z: 2}) {
+		t.Errorf("Expected 2/3/3, got %v", tile)
+	}
+	if tile := TileForPoint(-180, 90, 40); tile != (Tile{X: 0, Y: 0, Z: MaxTileZoom}) {
+		t.Errorf("Expected 30/0/0, got %v", tile)
+	}
+
+	bbox := Tile{}.BoundingBox()
+	expected := BoundingBox{-180, -MaxMercatorLatitude, 180, MaxMercatorLatitude}
+	if !EqualsWithin(bbox.Geometry(), expected.Geometry(), 1e-9) {
+		t.Errorf("Expected %v, got %v", expected, bbox)
+	}
+	tile := TileForPoint(-122.4194, 37.7749, 12)
+	if !tile.BoundingBox().ContainsPoint(NewPoint([]float64{-122.4194, 37.7749})) {
+		t.Errorf("Expected %v to contain its point", tile.BoundingBox())
+	}
+}
+
+func TestTilesCoveringBbox(t *testing.T) {
+	// Tiles only touching the east and south edges are left out
+	if result := tilesString(TilesCoveringBbox(BoundingBox{0, 0, 45, 40}, 3)); result != "3/4/3 " {
+		t.Errorf("Expected 3/4/3, got %v", result)
+	}
+	if result := tilesString(TilesCoveringBbox(BoundingBox{170, -10, -170, 10}, 3)); result != "3/0/3 3/0/4 3/7/3 3/7/4 " {
+		t.Errorf("Expected tiles on both sides of the antimeridian, got %v", result)
+	}
+	if result := TilesCoveringBbox(BoundingBox{-180, -90, 180, 90}, 2); len(result) != 16 {
+		t.Errorf("Expected 16 tiles, got %v", tilesString(result))
+	}
+	if result := TilesCoveringBbox(BoundingBox{10, 10, 10, 10}, 4); len(result) != 1 {
+		t.Errorf("Expected a single tile for a point, got %v", tilesString(result))
+	}
+}
+
+func TestTilesCoveringGeometry(t *testing.T) {
+	line := NewLineString([][]float64{{1, 1}, {40, 40}})
+	tiles := TilesCoveringGeometry(line, 6)
+	boxTiles := TilesCoveringBbox(line.ForceBbox(), 6)
+	if len(tiles) == 0 || len(tiles) >= len(boxTiles) {
+		t.Errorf("Expected fewer tiles than the bbox, got %v and %v", len(tiles), len(boxTiles))
+	}
+
+	triangle := NewPolygon([][][]float64{{{-20, -20}, {30, -10}, {0, 40}, {-20, -20}}})
+	for _, zoom := range []int{0, 3, 6} {
+		var expected []Tile
+		for _, tile := range TilesCoveringBbox(triangle.ForceBbox(), zoom) {
+			if Intersects(triangle, tile.BoundingBox().Geometry()) {
+				expected = append(expected, tile)
+			}
+		}
+		if result := TilesCoveringGeometry(triangle, zoom); tilesString(result) != tilesString(expected) {
+			t.Errorf("Zoom %v: expected %v tiles, got %v", zoom, len(expected), len(result))
+		}
+	}
+
+	if result := tilesString(TilesCoveringGeometry(NewPoint([]float64{-122.4194, 37.7749}), 12)); result != "12/655/1583 " {
+		t.Errorf("Expected 12/655/1583, got %v", result)
+	}
+	// Neighbouring tiles that only touch the geometry along an edge are not covered
+	square := Tile{X: 2, Y: 1, Z: 2}.BoundingBox().Geometry()
+	if result := tilesString(TilesCoveringGeometry(square, 2)); result != "2/2/1 " {
+		t.Errorf("Expected 2/2/1, got %v", result)
+	}
+	if result := TilesCoveringGeometry(square, 3); len(result) != 4 {
+		t.Errorf("Expected 4 tiles, got %v", tilesString(result))
+	}
+	if result := tilesString(TilesCoveringGeometry(NewLineString([][]float64{{10, 0}, {20, 0}}), 1)); result != "1/1/1 " {
+		t.Errorf("Expected a line along an edge to cover 1/1/1, got %v", result)
+	}
+
+	// Lines crossing the antimeridian do not cover the rest of the world
+	crossing := NewLineString([][]float64{{170, 10}, {-170, 10}})
+	if result := tilesString(TilesCoveringGeometry(crossing, 2)); result != "2/0/1 2/3/1 " {
+		t.Errorf("Expected 2/0/1 and 2/3/1, got %v", result)
+	}
+
+	if result := TilesCoveringGeometry(NewLineString([][]float64{}), 3); len(result) != 0 {
+		t.Errorf("Expected no tiles, got %v", result)
+	}
+}
@@ -0,0 +1,359 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// DefaultTileTolerance is a typical simplification tolerance for a TileIndex, in tile units
+const DefaultTileTolerance = 3
+
+const (
+	// indexMaxZoom is the deepest zoom level that tiles are split to when a TileIndex is made
+	indexMaxZoom = 5
+	// indexMaxPoints is the number of positions a tile may hold without being split when a TileIndex is made
+	indexMaxPoints = 100000
+)
+
+// TileIndexOptions configures a TileIndex
+type TileIndexOptions struct {
+	// Extent is the number of units across a tile, 4096 if zero
+	Extent int
+	// Buffer is the number of units around a tile that features are kept in, 64 if zero
+	Buffer int
+	// Tolerance is how far in tile units lines may be simplified, such as
+	// DefaultTileTolerance. Lines are not simplified if it is zero or negative.
+	Tolerance float64
+}
+
+// TileIndex serves the features of a FeatureCollection as vector tiles.
+// When the index is made, the features are split at the antimeridian,
+// projected to Web Mercator, prepared for simplification, and cut into tiles
+// down to the zoom level at which each tile is small. Deeper tiles are cut
+// from the nearest tile above them when they are first requested, and kept
+// for later requests.
+// A TileIndex is safe for concurrent use once it has been made.
+type TileIndex struct {
+	options TileIndexOptions
+	mutex   sync.Mutex
+	tiles   map[Tile][]*Feature
+}
+
+// NewTileIndex returns a TileIndex of the features in the collection,
+// which may be nil for an empty index. The collection is not modified.
+func NewTileIndex(fc *FeatureCollection, options TileIndexOptions) *TileIndex {
+	if options.Extent <= 0 {
+		options.Extent = DefaultMVTExtent
+	}
+	if options.Buffer <= 0 {
+		options.Buffer = 64
+	}
+
+	var projected []*Feature
+	if fc != nil {
+		for _, feature := range fc.Features {
+			if feature == nil || feature.Geometry == nil {
+				continue
+			}
+			geometry, err := MapCoordinates(SplitAntimeridian(feature.resolvedGeometry()), func(position []float64) []float64 {
+				x, y := mercatorFraction(position[0], position[1])
+				return []float64{x, y, math.Inf(1)}
+			})
+			if err != nil {
+				continue
+			}
+			if bbox := assignImportance(geometry, emptyRect()); math.IsInf(bbox[0], 1) {
+				continue
+			}
+			// Copies a world away appear in the buffer beside the antimeridian
+			for _, offset := range []float64{0, -1, 1} {
+				copied := geometry
+				if offset != 0 {
+					copied, _ = MapCoordinates(geometry, func(position []float64) []float64 {
+						return []float64{position[0] + offset, position[1], position[2]}
+					})
+				}
+				projected = append(projected, &Feature{Type: FEATURE, Geometry: copied, ID: feature.ID, Properties: feature.Properties})
+			}
+		}
+	}
+	index := &TileIndex{options: options, tiles: make(map[Tile][]*Feature)}
+	index.split(Tile{}, index.clip(projected, Tile{}))
+	return index
+}
+
+// split keeps the features of a tile, and cuts them into its children
+// while the tile is shallow enough and holds too many positions
+func (index *TileIndex) split(tile Tile, features []*Feature) {
+	index.tiles[tile] = features
+	if tile.Z >= indexMaxZoom {
+		return
+	}
+	count := 0
+	for _, feature := range features {
+		EachCoordinate(feature.Geometry, func([]float64) { count++ })
+	}
+	if count <= indexMaxPoints {
+		return
+	}
+	for _, child := range tile.children() {
+		index.split(child, index.clip(features, child))
+	}
+}
+
+// features returns the projected features of a tile, cutting it and the
+// tiles above it from the nearest tile that has already been cut
+func (index *TileIndex) features(tile Tile) []*Feature {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	var missing []Tile
+	features, ok := index.tiles[tile]
+	for !ok {
+		missing = append(missing, tile)
+		tile = tile.parent()
+		features, ok = index.tiles[tile]
+	}
+	for inx := len(missing) - 1; inx >= 0; inx-- {
+		features = index.clip(features, missing[inx])
+		index.tiles[missing[inx]] = features
+	}
+	return features
+}
+
+// clip returns the parts of projected features within a tile and its buffer.
+// Positions on the edge of the buffer are always kept by simplification,
+// so that clipped lines and rings keep their extent.
+func (index *TileIndex) clip(features []*Feature, tile Tile) []*Feature {
+	n := float64(int(1) << uint(tile.Z))
+	margin := float64(index.options.Buffer) / float64(index.options.Extent)
+	rect := rtreeRect{
+		(float64(tile.X) - margin) / n, (float64(tile.Y) - margin) / n,
+		(float64(tile.X+1) + margin) / n, (float64(tile.Y+1) + margin) / n}
+	var result []*Feature
+	for _, feature := range features {
+		clipped := clipGeometry(feature.Geometry, rect)
+		if clipped == nil {
+			continue
+		}
+		EachCoordinate(clipped, func(position []float64) {
+			if position[0] == rect[0] || position[0] == rect[2] || position[1] == rect[1] || position[1] == rect[3] {
+				position[2] = math.Inf(1)
+			}
+		})
+		result = append(result, &Feature{Type: FEATURE, Geometry: clipped, ID: feature.ID, Properties: feature.Properties})
+	}
+	return result
+}
+
+// GetTile returns the features of a tile with their geometries in tile units,
+// from 0 to the extent, rounded to whole units. Features within the buffer
+// around the tile are included, and features near the antimeridian are
+// repeated on the other side of it. The features share their properties
+// with the source collection.
+func (index *TileIndex) GetTile(z, x, y int) (*FeatureCollection, error) {
+	if z < 0 || z > MaxTileZoom {
+		return nil, fmt.Errorf("Zoom level %v must be between 0 and %v", z, MaxTileZoom)
+	}
+	n := 1 << uint(z)
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return nil, fmt.Errorf("Tile %v/%v/%v does not exist", z, x, y)
+	}
+
+	extent := float64(index.options.Extent)
+	var sqTolerance float64
+	if index.options.Tolerance > 0 {
+		sqTolerance = math.Pow(index.options.Tolerance/(float64(n)*extent), 2)
+	}
+	toTile := func(position []float64) []float64 {
+		return []float64{
+			math.Round((position[0]*float64(n) - float64(x)) * extent),
+			math.Round((position[1]*float64(n) - float64(y)) * extent)}
+	}
+	features := []*Feature{}
+	for _, feature := range index.features(Tile{x, y, z}) {
+		simplified := simplifyProjected(feature.Geometry, sqTolerance)
+		if simplified == nil {
+			continue
+		}
+		inTile, err := MapCoordinates(simplified, toTile)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, &Feature{Type: FEATURE, Geometry: inTile, ID: feature.ID, Properties: feature.Properties})
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// GetTileMVT returns a tile encoded as a Mapbox Vector Tile with a single layer
+func (index *TileIndex) GetTileMVT(z, x, y int, layerName string) ([]byte, error) {
+	fc, err := index.GetTile(z, x, y)
+	if err != nil {
+		return nil, err
+	}
+	return encodeMVTTile([][]byte{encodeMVTLayer(layerName, index.options.Extent, fc.Features)}), nil
+}
+
+// assignImportance sets the third ordinate of each position of a projected
+// geometry to the squared distance at which Douglas-Peucker simplification
+// would keep it, with the ends of lines and rings always kept,
+// and returns the rectangle extended to cover the geometry
+func assignImportance(geometry interface{}, rect rtreeRect) rtreeRect {
+	extend := func(positions [][]float64) {
+		for _, position := range positions {
+			rect = rect.extend(rtreeRect{position[0], position[1], position[0], position[1]})
+		}
+	}
+	switch typed := geometry.(type) {
+	case *Point:
+		extend([][]float64{typed.Coordinates})
+	case *MultiPoint:
+		extend(typed.Coordinates)
+	case *LineString:
+		douglasPeucker(typed.Coordinates)
+		extend(typed.Coordinates)
+	case *MultiLineString:
+		for _, line := range typed.Coordinates {
+			douglasPeucker(line)
+			extend(line)
+		}
+	case *Polygon:
+		for _, ring := range typed.Coordinates {
+			douglasPeucker(ring)
+			extend(ring)
+		}
+	case *MultiPolygon:
+		for _, polygon := range typed.Coordinates {
+			for _, ring := range polygon {
+				douglasPeucker(ring)
+				extend(ring)
+			}
+		}
+	case *GeometryCollection:
+		for _, curr := range typed.Geometries {
+			rect = assignImportance(curr, rect)
+		}
+	}
+	return rect
+}
+
+// douglasPeucker records the importance of each interior position of a line
+func douglasPeucker(line [][]float64) {
+	if len(line) < 3 {
+		return
+	}
+	type span struct{ first, last int }
+	stack := []span{{0, len(line) - 1}}
+	for len(stack) > 0 {
+		curr := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		best, bestDistance := -1, -1.0
+		for inx := curr.first + 1; inx < curr.last; inx++ {
+			if distance := squaredSegmentDistance(line[inx], line[curr.first], line[curr.last]); distance > bestDistance {
+				best, bestDistance = inx, distance
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		line[best][2] = bestDistance
+		stack = append(stack, span{curr.first, best}, span{best, curr.last})
+	}
+}
+
+// squaredSegmentDistance returns the squared planar distance from p to the segment a-b
+func squaredSegmentDistance(p, a, b []float64) float64 {
+	_, _, distance := nearestOnSegment(p, a, b, Planar)
+	return distance * distance
+}
+
+// simplifyProjected returns a projected geometry without the positions whose
+// importance is below the squared tolerance, or nil if nothing useful remains
+func simplifyProjected(geometry interface{}, sqTolerance float64) interface{} {
+	keep := func(positions [][]float64, minimum int) [][]float64 {
+		var result [][]float64
+		for _, position := range positions {
+			if position[2] > sqTolerance || sqTolerance == 0 {
+				result = append(result, position)
+			}
+		}
+		if len(result) < minimum {
+			return nil
+		}
+		return result
+	}
+	switch typed := geometry.(type) {
+	case *Point, *MultiPoint:
+		return geometry
+	case *LineString:
+		return linesGeometry(nonEmpty(keep(typed.Coordinates, 2)))
+	case *MultiLineString:
+		var lines [][][]float64
+		for _, line := range typed.Coordinates {
+			lines = append(lines, nonEmpty(keep(line, 2))...)
+		}
+		return linesGeometry(lines)
+	case *Polygon:
+		return polygonsGeometry(simplifyPolygon(typed.Coordinates, keep))
+	case *MultiPolygon:
+		var polygons [][][][]float64
+		for _, polygon := range typed.Coordinates {
+			polygons = append(polygons, simplifyPolygon(polygon, keep)...)
+		}
+		return polygonsGeometry(polygons)
+	case *GeometryCollection:
+		var geometries []interface{}
+		for _, curr := range typed.Geometries {
+			if simplified := simplifyProjected(curr, sqTolerance); simplified != nil {
+				geometries = append(geometries, simplified)
+			}
+		}
+		if len(geometries) > 0 {
+			return NewGeometryCollection(geometries)
+		}
+	}
+	return nil
+}
+
+func nonEmpty(line [][]float64) [][][]float64 {
+	if line == nil {
+		return nil
+	}
+	return [][][]float64{line}
+}
+
+// simplifyPolygon drops the polygon if its exterior ring collapses,
+// and drops holes that collapse
+func simplifyPolygon(polygon [][][]float64, keep func([][]float64, int) [][]float64) [][][][]float64 {
+	var result [][][]float64
+	for inx, ring := range polygon {
+		simplified := keep(ring, 4)
+		if simplified == nil {
+			if inx == 0 {
+				return nil
+			}
+			continue
+		}
+		result = append(result, simplified)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return [][][][]float64{result}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTileIndex(t *testing.T) {
+	tile := TileForPoint(10, 10, 4)
+	middleX, middleY := mercatorLonLat((float64(tile.X)+0.5)/16, (float64(tile.Y)+0.5)/16)
+	properties := map[string]interface{}{"name": "centre"}
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{middleX, middleY}), "a", properties),
+		NewFeature(NewPoint([]float64{-100, -40}), "b", nil),
+		NewFeature(nil, "c", nil)})
+	index := NewTileIndex(fc, TileIndexOptions{})
+
+	result, err := index.GetTile(tile.Z, tile.X, tile.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Features) != 1 {
+		t.Fatalf("Expected 1 feature, got %v", result.String())
+	}
+	feature := result.Features[0]
+	if feature.ID != "a" || feature.PropertyString("name") != "centre" {
+		t.Errorf("Expected the ID and properties to be kept, got %v", feature.String())
+	}
+	point := feature.Geometry.(*Point)
+	if len(point.Coordinates) != 2 || point.Coordinates[0] != 2048 || point.Coordinates[1] != 2048 {
+		t.Errorf("Expected the point in the middle of the tile, got %v", point.Coordinates)
+	}
+
+	if result, err = index.GetTile(0, 0, 0); err != nil || len(result.Features) != 2 {
+		t.Errorf("Expected both points in the world tile, got %v, %v", result, err)
+	}
+	for _, tile := range [][3]int{{-1, 0, 0}, {MaxTileZoom + 1, 0, 0}, {2, 4, 0}, {2, 0, -1}} {
+		if _, err = index.GetTile(tile[0], tile[1], tile[2]); err == nil {
+			t.Errorf("Expected an error for tile %v", tile)
+		}
+	}
+	if result, err = NewTileIndex(nil, TileIndexOptions{}).GetTile(0, 0, 0); err != nil || len(result.Features) != 0 {
+		t.Errorf("Expected an empty tile from an empty index, got %v, %v", result, err)
+	}
+}
+
+func TestTileIndexSimplification(t *testing.T) {
+	// A line along the equator with small zigzags
+	var coordinates [][]float64
+	for inx := 0; inx <= 100; inx++ {
+		coordinates = append(coordinates, []float64{float64(inx) / 10, float64(inx%2) * 0.001})
+	}
+	fc := NewFeatureCollection([]*Feature{NewFeature(NewLineString(coordinates), nil, nil)})
+
+	countPositions := func(index *TileIndex, z, x, y int) int {
+		result, err := index.GetTile(z, x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for _, feature := range result.Features {
+			EachCoordinate(feature.Geometry, func([]float64) { count++ })
+		}
+		return count
+	}
+
+	index := NewTileIndex(fc, TileIndexOptions{Tolerance: DefaultTileTolerance})
+	if count := countPositions(index, 0, 0, 0); count != 2 {
+		t.Errorf("Expected the zigzags to be simplified away at zoom 0, got %v positions", count)
+	}
+	tile := TileForPoint(5, 0.0005, 14)
+	if count := countPositions(index, tile.Z, tile.X, tile.Y); count < 3 {
+		t.Errorf("Expected the zigzags to be kept at zoom 14, got %v positions", count)
+	}
+	for _, tolerance := range []float64{0, -1} {
+		if count := countPositions(NewTileIndex(fc, TileIndexOptions{Tolerance: tolerance}), 0, 0, 0); count != 101 {
+			t.Errorf("Expected no simplification with a tolerance of %v, got %v positions", tolerance, count)
+		}
+	}
+
+	// Small polygons collapse rather than degenerate
+	polygon := NewPolygon([][][]float64{{{0, 0}, {0.001, 0}, {0.001, 0.001}, {0, 0.001}, {0, 0}}})
+	index = NewTileIndex(NewFeatureCollection([]*Feature{NewFeature(polygon, nil, nil)}), TileIndexOptions{Tolerance: DefaultTileTolerance})
+	if result, _ := index.GetTile(0, 0, 0); len(result.Features) != 0 {
+		t.Errorf("Expected the polygon to collapse at zoom 0, got %v", result.String())
+	}
+	if result, _ := index.GetTile(10, 512, 511); len(result.Features) != 1 {
+		t.Errorf("Expected the polygon at zoom 10, got %v", result.String())
+	}
+}
+
+func TestTileIndexBufferAndWrap(t *testing.T) {
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{179, 0.1}), "east", nil),
+		NewFeature(NewLineString([][]float64{{-30, 0.1}, {30, 0.1}}), "line", nil)})
+	index := NewTileIndex(fc, TileIndexOptions{Extent: 256, Buffer: 16})
+
+	// The point is just across the antimeridian from the western tile
+	result, err := index.GetTile(1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, feature := range result.Features {
+		if feature.ID == "east" {
+			found = true
+			if x := feature.Geometry.(*Point).Coordinates[0]; x != -1 {
+				t.Errorf("Expected the point west of the tile, got %v", x)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected the wrapped point in the buffer, got %v", result.String())
+	}
+
+	// The line is clipped to the buffer
+	result, _ = index.GetTile(1, 1, 0)
+	for _, feature := range result.Features {
+		if feature.ID != "line" {
+			continue
+		}
+		line := feature.Geometry.(*LineString).Coordinates
+		if line[0][0] != -16 || line[len(line)-1][0] != 43 {
+			t.Errorf("Expected the line clipped to the buffer, got %v", line)
+		}
+	}
+
+	data, err := index.GetTileMVT(1, 1, 0, "layer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := DecodeMVT(data, Tile{X: 1, Y: 0, Z: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer, ok := layers["layer"]; !ok || len(layer.Features) != 2 {
+		t.Errorf("Expected two features in the layer, got %v", layers)
+	}
+	if _, err = index.GetTileMVT(1, 2, 0, "layer"); err == nil {
+		t.Error("Expected an error for a missing tile")
+	}
+
+	// A line across the antimeridian is cut there rather than drawn around the world,
+	// and the piece on each side appears in the buffer of the other
+	crossing := NewFeatureCollection([]*Feature{NewFeature(NewLineString([][]float64{{170, 20}, {-170, 20}}), "crossing", nil)})
+	index = NewTileIndex(crossing, TileIndexOptions{Extent: 256, Buffer: 16})
+	expected := map[int]string{
+		0: "[[[0 227] [14 227]] [[-14 227] [0 227]]]",
+		1: "[[[242 227] [256 227]] [[256 227] [270 227]]]"}
+	for x, lines := range expected {
+		if result, err = index.GetTile(1, x, 0); err != nil {
+			t.Fatal(err)
+		}
+		var actual [][][]float64
+		for _, feature := range result.Features {
+			actual = append(actual, feature.Geometry.(*LineString).Coordinates)
+		}
+		if fmt.Sprint(actual) != lines {
+			t.Errorf("Expected %v in tile 1/%v/0, got %v", lines, x, fmt.Sprint(actual))
+		}
+	}
+	if result, _ = index.GetTile(1, 1, 1); len(result.Features) != 0 {
+		t.Errorf("Expected no line south of the equator, got %v", result.String())
+	}
+}
+
+func TestTileIndexCache(t *testing.T) {
+	var coordinates [][]float64
+	for inx := 0; inx <= 1000; inx++ {
+		coordinates = append(coordinates, []float64{float64(inx) / 100, float64(inx%7) / 100})
+	}
+	polygon := NewPolygon([][][]float64{{{-20, -20}, {20, -20}, {20, 20}, {-20, 20}, {-20, -20}}})
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewLineString(coordinates), "line", nil),
+		NewFeature(polygon, "polygon", nil)})
+	index := NewTileIndex(fc, TileIndexOptions{Tolerance: DefaultTileTolerance})
+
+	// Tiles are the same whether they are cut from the world tile or from a tile above them
+	tile := TileForPoint(5, 0.03, 12)
+	parent := NewTileIndex(fc, TileIndexOptions{Tolerance: DefaultTileTolerance})
+	if _, err := parent.GetTile(8, tile.X/16, tile.Y/16); err != nil {
+		t.Fatal(err)
+	}
+	for _, curr := range []*TileIndex{index, index, parent} {
+		result, err := curr.GetTile(tile.Z, tile.X, tile.Y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := NewTileIndex(fc, TileIndexOptions{Tolerance: DefaultTileTolerance}).GetTile(tile.Z, tile.X, tile.Y)
+		if result.String() != expected.String() {
+			t.Errorf("Expected %v, got %v", expected.String(), result.String())
+		}
+		if len(result.Features) != 2 {
+			t.Errorf("Expected the line and the polygon, got %v", result.String())
+		}
+	}
+
+	// The polygon covers the whole of a tile inside it, with its edges on the buffer
+	result, _ := index.GetTile(tile.Z, tile.X, tile.Y+8)
+	for _, feature := range result.Features {
+		if feature.ID == "polygon" {
+			expected := `{"type":"Polygon","coordinates":[[[-64,-64],[4160,-64],[4160,4160],[-64,4160],[-64,-64]]]}`
+			if ring := feature.Geometry.(*Polygon); Normalize(ring).(*Polygon).String() != expected {
+				t.Errorf("Expected %v, got %v", expected, ring.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,620 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TOPOLOGY is the type of a TopoJSON object
+const TOPOLOGY = "Topology"
+
+// The Topology object represents a TopoJSON topology.
+// Lines and rings are stored once, as arcs, and shared by the geometries
+// that reference them.
+type Topology struct {
+	Type      string                     `json:"type"`
+	Transform *TopologyTransform         `json:"transform,omitempty"`
+	Objects   map[string]*TopologyObject `json:"objects"`
+	Arcs      [][][]float64              `json:"arcs"`
+	Bbox      BoundingBox                `json:"bbox,omitempty"`
+}
+
+// TopologyTransform describes how the quantized positions of a Topology
+// are converted back into coordinates
+type TopologyTransform struct {
+	Scale     [2]float64 `json:"scale"`
+	Translate [2]float64 `json:"translate"`
+}
+
+// The TopologyObject represents a TopoJSON geometry object.
+// Points and MultiPoints have Coordinates; the other geometries refer to
+// arcs by index, with ^index (-index - 1) for an arc followed in reverse.
+// A geometry object with an empty Type is written as a null geometry.
+type TopologyObject struct {
+	Type        string                 `json:"type"`
+	ID          interface{}            `json:"id,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	Coordinates interface{}            `json:"coordinates,omitempty"`
+	Arcs        interface{}            `json:"arcs,omitempty"`
+	Geometries  []*TopologyObject      `json:"geometries,omitempty"`
+}
+
+// MarshalJSON writes a null type for a geometry object without one
+func (object *TopologyObject) MarshalJSON() ([]byte, error) {
+	type plain TopologyObject
+	var typeIfc interface{}
+	if object.Type != "" {
+		typeIfc = object.Type
+	}
+	return json.Marshal(struct {
+		Type interface{} `json:"type"`
+		*plain
+	}{typeIfc, (*plain)(object)})
+}
+
+// TopologyFromBytes constructs a Topology from a TopoJSON byte array
+// and returns its pointer
+func TopologyFromBytes(bytes []byte) (*Topology, error) {
+	var result Topology
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+	if result.Type != TOPOLOGY {
+		return nil, fmt.Errorf("Expected a %v but received %v", TOPOLOGY, result.Type)
+	}
+	return &result, nil
+}
+
+// String returns the string representation
+func (topology *Topology) String() string {
+	var result string
+	if bytes, err := json.Marshal(topology); err == nil {
+		result = string(bytes)
+	} else {
+		result = err.Error()
+	}
+	return result
+}
+
+// ToTopoJSON returns a Topology containing the features of the collection
+// as a GeometryCollection object with the name provided.
+// Lines and rings are cut where they meet others so that shared edges
+// are stored as a single arc.
+// If quantization is greater than one, positions are snapped to a grid of
+// that many values across the extent of the collection and the arcs are
+// delta-encoded; lines and rings that collapse on the grid are dropped.
+// The Topology shares no positions with the collection, which is not modified.
+func ToTopoJSON(fc *FeatureCollection, objectName string, quantization int) *Topology {
+	result := &Topology{Type: TOPOLOGY, Objects: make(map[string]*TopologyObject), Arcs: [][][]float64{}}
+	builder := &topologyBuilder{}
+	collection := &TopologyObject{Type: GEOMETRYCOLLECTION, Geometries: []*TopologyObject{}}
+	result.Objects[objectName] = collection
+	if fc == nil {
+		return result
+	}
+
+	extent := emptyRect()
+	EachCoordinate(fc, func(position []float64) {
+		extent = extent.extend(rtreeRect{position[0], position[1], position[0], position[1]})
+	})
+	if math.IsInf(extent[0], 1) {
+		extent = rtreeRect{}
+	} else {
+		result.Bbox = BoundingBox{extent[0], extent[1], extent[2], extent[3]}
+	}
+
+	var quantize func([]float64) []float64
+	if quantization > 1 {
+		transform := &TopologyTransform{Scale: [2]float64{1, 1}, Translate: [2]float64{extent[0], extent[1]}}
+		for axis := 0; axis < 2; axis++ {
+			if size := extent[axis+2] - extent[axis]; size > 0 {
+				transform.Scale[axis] = size / float64(quantization-1)
+			}
+		}
+		quantize = func(position []float64) []float64 {
+			quantized := append([]float64{}, position...)
+			for axis := 0; axis < 2; axis++ {
+				quantized[axis] = math.Round((position[axis] - transform.Translate[axis]) / transform.Scale[axis])
+			}
+			return quantized
+		}
+		result.Transform = transform
+	}
+
+	for _, feature := range fc.Features {
+		if feature == nil {
+			continue
+		}
+		geometry := feature.resolvedGeometry()
+		if quantize != nil {
+			quantized, err := MapCoordinates(geometry, quantize)
+			if err != nil {
+				// Not a geometry that can be stored in a Topology
+				quantized = nil
+			}
+			geometry = cleanGeometry(quantized)
+		}
+		object := builder.object(geometry)
+		object.ID = feature.ID
+		object.Properties = feature.Properties
+		collection.Geometries = append(collection.Geometries, object)
+	}
+
+	result.Arcs = builder.arcs()
+	if quantize != nil {
+		for _, arc := range result.Arcs {
+			for inx := len(arc) - 1; inx > 0; inx-- {
+				arc[inx][0] -= arc[inx-1][0]
+				arc[inx][1] -= arc[inx-1][1]
+			}
+		}
+	}
+	for _, object := range collection.Geometries {
+		resolveTopologyArcs(object)
+	}
+	return result
+}
+
+// FromTopoJSON returns the features of the named object of the Topology.
+// A GeometryCollection object yields a feature for each of its geometries,
+// and any other object yields a single feature.
+// If the name is empty, the features of every object are returned,
+// ordered by object name.
+func FromTopoJSON(topology *Topology, objectName string) (*FeatureCollection, error) {
+	if topology == nil {
+		return nil, errors.New("Cannot read features from a nil Topology")
+	}
+	var names []string
+	if objectName == "" {
+		for name := range topology.Objects {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	} else if _, ok := topology.Objects[objectName]; ok {
+		names = []string{objectName}
+	} else {
+		return nil, fmt.Errorf("Topology has no object named %v", objectName)
+	}
+
+	reader := &topologyReader{transform: topology.Transform}
+	for _, arc := range topology.Arcs {
+		reader.arcs = append(reader.arcs, reader.decodeArc(arc))
+	}
+
+	features := []*Feature{}
+	for _, name := range names {
+		object := topology.Objects[name]
+		if object == nil {
+			continue
+		}
+		members := []*TopologyObject{object}
+		if object.Type == GEOMETRYCOLLECTION {
+			members = object.Geometries
+		}
+		for _, member := range members {
+			if member == nil {
+				continue
+			}
+			geometry, err := reader.geometry(member)
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, NewFeature(geometry, member.ID, member.Properties))
+		}
+	}
+	return NewFeatureCollection(features), nil
+}
+
+// topologyLine is a line or ring awaiting its arcs
+type topologyLine struct {
+	positions [][]float64
+	ring      bool
+	arcs      []int
+}
+
+type topologyBuilder struct {
+	lines []*topologyLine
+}
+
+func (builder *topologyBuilder) line(positions [][]float64, ring bool) *topologyLine {
+	result := &topologyLine{positions: positions, ring: ring}
+	builder.lines = append(builder.lines, result)
+	return result
+}
+
+func (builder *topologyBuilder) multiLine(lines [][][]float64, ring bool) []*topologyLine {
+	result := make([]*topologyLine, len(lines))
+	for inx, line := range lines {
+		result[inx] = builder.line(line, ring)
+	}
+	return result
+}
+
+// object returns a geometry object whose arcs are placeholders
+// until resolveTopologyArcs is called
+func (builder *topologyBuilder) object(geometry interface{}) *TopologyObject {
+	result := &TopologyObject{}
+	switch typed := geometry.(type) {
+	case *Point:
+		result.Type = POINT
+		result.Coordinates = clone1(typed.Coordinates)
+	case *MultiPoint:
+		result.Type = MULTIPOINT
+		result.Coordinates = clone2(typed.Coordinates)
+	case *LineString:
+		result.Type = LINESTRING
+		result.Arcs = builder.line(typed.Coordinates, false)
+	case *MultiLineString:
+		result.Type = MULTILINESTRING
+		result.Arcs = builder.multiLine(typed.Coordinates, false)
+	case *Polygon:
+		if len(typed.Coordinates) > 0 {
+			result.Type = POLYGON
+			result.Arcs = builder.multiLine(typed.Coordinates, true)
+		}
+	case *MultiPolygon:
+		if len(typed.Coordinates) > 0 {
+			result.Type = MULTIPOLYGON
+			polygons := make([][]*topologyLine, len(typed.Coordinates))
+			for inx, polygon := range typed.Coordinates {
+				polygons[inx] = builder.multiLine(polygon, true)
+			}
+			result.Arcs = polygons
+		}
+	case *GeometryCollection:
+		result.Type = GEOMETRYCOLLECTION
+		result.Geometries = []*TopologyObject{}
+		for _, curr := range typed.Geometries {
+			result.Geometries = append(result.Geometries, builder.object(curr))
+		}
+	}
+	return result
+}
+
+// arcs cuts the lines at the junctions where they meet one another,
+// records the arcs of each line and returns the distinct arcs
+func (builder *topologyBuilder) arcs() [][][]float64 {
+	junctions := builder.junctions()
+	result := [][][]float64{}
+	indexes := make(map[string]int)
+	for _, line := range builder.lines {
+		line.arcs = []int{}
+		for _, arc := range cutTopologyLine(line, junctions) {
+			if inx, ok := indexes[topologyArcKey(arc, false)]; ok {
+				line.arcs = append(line.arcs, inx)
+			} else if inx, ok := indexes[topologyArcKey(arc, true)]; ok {
+				line.arcs = append(line.arcs, ^inx)
+			} else {
+				inx = len(result)
+				indexes[topologyArcKey(arc, false)] = inx
+				copied := make([][]float64, len(arc))
+				for pinx, position := range arc {
+					copied[pinx] = append([]float64{}, position...)
+				}
+				result = append(result, copied)
+				line.arcs = append(line.arcs, inx)
+			}
+		}
+	}
+	return result
+}
+
+type topologyKey [2]float64
+
+func topologyKeyOf(position []float64) topologyKey {
+	return topologyKey{position[0], position[1]}
+}
+
+// junctions returns the positions where lines begin, end, meet or part:
+// a position is a junction if it is reached from different neighbours
+func (builder *topologyBuilder) junctions() map[topologyKey]bool {
+	result := make(map[topologyKey]bool)
+	neighbours := make(map[topologyKey][2]topologyKey)
+	for _, line := range builder.lines {
+		positions := line.positions
+		count := len(positions)
+		if line.ring {
+			// The closing position repeats the first
+			count--
+		}
+		for inx := 0; inx < count; inx++ {
+			key := topologyKeyOf(positions[inx])
+			if result[key] {
+				continue
+			}
+			var previous, next int
+			if line.ring {
+				previous, next = (inx+count-1)%count, (inx+1)%count
+			} else if inx == 0 || inx == count-1 {
+				result[key] = true
+				continue
+			} else {
+				previous, next = inx-1, inx+1
+			}
+			pair := [2]topologyKey{topologyKeyOf(positions[previous]), topologyKeyOf(positions[next])}
+			if pair[1][0] < pair[0][0] || (pair[1][0] == pair[0][0] && pair[1][1] < pair[0][1]) {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			if seen, ok := neighbours[key]; !ok {
+				neighbours[key] = pair
+			} else if seen != pair {
+				result[key] = true
+			}
+		}
+	}
+	return result
+}
+
+// cutTopologyLine splits a line at its junctions.
+// A ring is first rotated to start at a junction, or if it has none,
+// at its least position so that equal rings produce equal arcs.
+func cutTopologyLine(line *topologyLine, junctions map[topologyKey]bool) [][][]float64 {
+	positions := line.positions
+	if len(positions) == 0 {
+		return nil
+	}
+	if line.ring && len(positions) > 1 {
+		count := len(positions) - 1
+		start, least := -1, 0
+		for inx := 0; inx < count; inx++ {
+			if junctions[topologyKeyOf(positions[inx])] {
+				start = inx
+				break
+			}
+			if positions[inx][0] < positions[least][0] || (positions[inx][0] == positions[least][0] && positions[inx][1] < positions[least][1]) {
+				least = inx
+			}
+		}
+		if start < 0 {
+			start = least
+		}
+		rotated := make([][]float64, 0, len(positions))
+		rotated = append(rotated, positions[start:count]...)
+		rotated = append(rotated, positions[:start]...)
+		positions = append(rotated, positions[start])
+	}
+
+	var result [][][]float64
+	first := 0
+	for inx := 1; inx < len(positions); inx++ {
+		if inx == len(positions)-1 || junctions[topologyKeyOf(positions[inx])] {
+			result = append(result, positions[first:inx+1])
+			first = inx
+		}
+	}
+	if len(result) == 0 {
+		// A lone position
+		result = append(result, positions)
+	}
+	return result
+}
+
+func topologyArcKey(arc [][]float64, reverse bool) string {
+	var builder strings.Builder
+	for inx := range arc {
+		position := arc[inx]
+		if reverse {
+			position = arc[len(arc)-1-inx]
+		}
+		builder.WriteString(strconv.FormatFloat(position[0], 'g', -1, 64))
+		builder.WriteByte(',')
+		builder.WriteString(strconv.FormatFloat(position[1], 'g', -1, 64))
+		builder.WriteByte(';')
+	}
+	return builder.String()
+}
+
+// resolveTopologyArcs replaces the placeholder arcs of the object
+// with the indexes of the arcs
+func resolveTopologyArcs(object *TopologyObject) {
+	switch typed := object.Arcs.(type) {
+	case *topologyLine:
+		object.Arcs = typed.arcs
+	case []*topologyLine:
+		object.Arcs = topologyLineArcs(typed)
+	case [][]*topologyLine:
+		polygons := make([][][]int, len(typed))
+		for inx, polygon := range typed {
+			polygons[inx] = topologyLineArcs(polygon)
+		}
+		object.Arcs = polygons
+	}
+	for _, geometry := range object.Geometries {
+		resolveTopologyArcs(geometry)
+	}
+}
+
+func topologyLineArcs(lines []*topologyLine) [][]int {
+	result := make([][]int, len(lines))
+	for inx, line := range lines {
+		result[inx] = line.arcs
+	}
+	return result
+}
+
+type topologyReader struct {
+	transform *TopologyTransform
+	arcs      [][][]float64
+}
+
+// decodeArc returns a copy of the arc in coordinates,
+// undoing any quantization and delta encoding
+func (reader *topologyReader) decodeArc(arc [][]float64) [][]float64 {
+	result := make([][]float64, len(arc))
+	var x, y float64
+	for inx, position := range arc {
+		result[inx] = append([]float64{}, position...)
+		if reader.transform != nil && len(position) >= 2 {
+			x += position[0]
+			y += position[1]
+			result[inx][0], result[inx][1] = x, y
+			result[inx] = reader.position(result[inx])
+		}
+	}
+	return result
+}
+
+// position returns a copy of the position in coordinates
+func (reader *topologyReader) position(position []float64) []float64 {
+	result := append([]float64{}, position...)
+	if reader.transform != nil && len(result) >= 2 {
+		for axis := 0; axis < 2; axis++ {
+			result[axis] = result[axis]*reader.transform.Scale[axis] + reader.transform.Translate[axis]
+		}
+	}
+	return result
+}
+
+// line joins the arcs provided, dropping the position each shares with the last
+func (reader *topologyReader) line(indexes []int) ([][]float64, error) {
+	result := [][]float64{}
+	for _, index := range indexes {
+		arcIndex := index
+		if index < 0 {
+			arcIndex = ^index
+		}
+		if arcIndex >= len(reader.arcs) {
+			return nil, fmt.Errorf("Topology has no arc %v", index)
+		}
+		arc := reader.arcs[arcIndex]
+		for inx := range arc {
+			position := arc[inx]
+			if index < 0 {
+				position = arc[len(arc)-1-inx]
+			}
+			if inx == 0 && len(result) > 0 {
+				continue
+			}
+			result = append(result, append([]float64{}, position...))
+		}
+	}
+	return result, nil
+}
+
+func (reader *topologyReader) lines(indexes [][]int) ([][][]float64, error) {
+	result := make([][][]float64, len(indexes))
+	for inx, curr := range indexes {
+		line, err := reader.line(curr)
+		if err != nil {
+			return nil, err
+		}
+		result[inx] = line
+	}
+	return result, nil
+}
+
+func (reader *topologyReader) positions(input [][]float64) [][]float64 {
+	result := make([][]float64, len(input))
+	for inx, position := range input {
+		result[inx] = reader.position(position)
+	}
+	return result
+}
+
+// geometry returns the GeoJSON geometry of the object,
+// or nil for a null geometry
+func (reader *topologyReader) geometry(object *TopologyObject) (interface{}, error) {
+	switch object.Type {
+	case "":
+		return nil, nil
+	case POINT:
+		var coordinates []float64
+		if err := convertTopologyMember(object.Coordinates, &coordinates); err != nil {
+			return nil, err
+		}
+		return NewPoint(reader.position(coordinates)), nil
+	case MULTIPOINT:
+		var coordinates [][]float64
+		if err := convertTopologyMember(object.Coordinates, &coordinates); err != nil {
+			return nil, err
+		}
+		return NewMultiPoint(reader.positions(coordinates)), nil
+	case LINESTRING:
+		var arcs []int
+		if err := convertTopologyMember(object.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		line, err := reader.line(arcs)
+		if err != nil {
+			return nil, err
+		}
+		return NewLineString(line), nil
+	case MULTILINESTRING, POLYGON:
+		var arcs [][]int
+		if err := convertTopologyMember(object.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		lines, err := reader.lines(arcs)
+		if err != nil {
+			return nil, err
+		}
+		if object.Type == POLYGON {
+			return NewPolygon(lines), nil
+		}
+		return NewMultiLineString(lines), nil
+	case MULTIPOLYGON:
+		var arcs [][][]int
+		if err := convertTopologyMember(object.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		polygons := make([][][][]float64, len(arcs))
+		for inx, polygon := range arcs {
+			lines, err := reader.lines(polygon)
+			if err != nil {
+				return nil, err
+			}
+			polygons[inx] = lines
+		}
+		return NewMultiPolygon(polygons), nil
+	case GEOMETRYCOLLECTION:
+		geometries := []interface{}{}
+		for _, member := range object.Geometries {
+			if member == nil {
+				continue
+			}
+			geometry, err := reader.geometry(member)
+			if err != nil {
+				return nil, err
+			}
+			if geometry != nil {
+				geometries = append(geometries, geometry)
+			}
+		}
+		return NewGeometryCollection(geometries), nil
+	}
+	return nil, fmt.Errorf("Unknown TopoJSON geometry type %v", object.Type)
+}
+
+// convertTopologyMember converts the coordinates or arcs of an object,
+// which are generic values when unmarshaled, into the type expected
+func convertTopologyMember(input interface{}, output interface{}) error {
+	bytes, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(bytes, output); err != nil {
+		return fmt.Errorf("Invalid TopoJSON member %v: %v", string(bytes), err)
+	}
+	return nil
+}
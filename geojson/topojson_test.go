@@ -0,0 +1,181 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func squareFeature(id interface{}, west, south float64) *Feature {
+	return NewFeature(NewPolygon([][][]float64{{
+		{west, south}, {west + 1, south}, {west + 1, south + 1}, {west, south + 1}, {west, south}}}),
+		id, map[string]interface{}{"west": west})
+}
+
+func TestTopoJSONSharedEdges(t *testing.T) {
+	fc := NewFeatureCollection([]*Feature{squareFeature("a", 0, 0), squareFeature("b", 1, 0)})
+	topology := ToTopoJSON(fc, "squares", 0)
+	if len(topology.Arcs) != 3 {
+		t.Fatalf("Expected the shared edge to be stored once in 3 arcs, got %v", topology.Arcs)
+	}
+	if topology.Transform != nil || !topology.Bbox.Equals(BoundingBox{0, 0, 2, 1}) {
+		t.Errorf("Expected no transform and the extent of the data, got %v", topology.String())
+	}
+	geometries := topology.Objects["squares"].Geometries
+	if actual := fmt.Sprint(geometries[0].Arcs, geometries[1].Arcs); actual != "[[0 1]] [[2 -1]]" {
+		t.Errorf("Expected the second square to reverse the shared arc, got %v", actual)
+	}
+
+	bytes, err := json.Marshal(topology)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topology, err = TopologyFromBytes(bytes); err != nil {
+		t.Fatal(err)
+	}
+	result, err := FromTopoJSON(topology, "squares")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Features) != 2 {
+		t.Fatalf("Expected 2 features, got %v", result.String())
+	}
+	for inx, feature := range result.Features {
+		expected := fc.Features[inx]
+		if feature.ID != expected.ID || feature.PropertyFloat("west") != expected.PropertyFloat("west") {
+			t.Errorf("Expected the ID and properties of %v, got %v", expected.String(), feature.String())
+		}
+		if !EqualsTopologically(feature.Geometry, expected.Geometry, 0) {
+			t.Errorf("Expected %v, got %v", expected.Geometry, feature.Geometry)
+		}
+	}
+}
+
+func TestTopoJSONGeometries(t *testing.T) {
+	fc := NewFeatureCollection([]*Feature{
+		NewFeature(NewPoint([]float64{5, 5}), 1.0, nil),
+		NewFeature(NewMultiPoint([][]float64{{1, 2}, {3, 4}}), 2.0, nil),
+		NewFeature(NewLineString([][]float64{{0, 0}, {1, 0}, {2, 0}}), 3.0, nil),
+		NewFeature(NewMultiLineString([][][]float64{{{1, 0}, {1, 5}}, {{12, 12}, {14, 14}}}), 4.0, nil),
+		NewFeature(NewMultiPolygon([][][][]float64{
+			{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, {{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}},
+			{{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}}}), 5.0, nil),
+		NewFeature(NewGeometryCollection([]interface{}{NewPoint([]float64{1, 1}), NewLineString([][]float64{{2, 0}, {3, 0}})}), 6.0, nil),
+		NewFeature(nil, 7.0, map[string]interface{}{"empty": true})})
+
+	topology := ToTopoJSON(fc, "all", 0)
+	bytes, err := json.Marshal(topology)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topology, err = TopologyFromBytes(bytes); err != nil {
+		t.Fatal(err)
+	}
+	result, err := FromTopoJSON(topology, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Features) != len(fc.Features) {
+		t.Fatalf("Expected %v features, got %v", len(fc.Features), result.String())
+	}
+	for inx, feature := range result.Features {
+		expected := fc.Features[inx]
+		if feature.ID != expected.ID {
+			t.Errorf("Expected ID %v, got %v", expected.ID, feature.ID)
+		}
+		if expected.Geometry == nil {
+			if feature.Geometry != nil || feature.Properties["empty"] != true {
+				t.Errorf("Expected a feature without geometry, got %v", feature.String())
+			}
+			continue
+		}
+		if !EqualsTopologically(feature.Geometry, expected.Geometry, 0) {
+			t.Errorf("Expected %v, got %v", expected.Geometry, feature.Geometry)
+		}
+	}
+
+	// The first line is cut where the second begins, and the hole
+	// of the first polygon is the shell of the second
+	if len(topology.Arcs) != 7 {
+		t.Errorf("Expected 7 arcs, got %v", topology.Arcs)
+	}
+
+	// Unquantized points are copied
+	points := NewFeatureCollection([]*Feature{fc.Features[0], fc.Features[1]})
+	copied := ToTopoJSON(points, "points", 0).Objects["points"].Geometries
+	copied[0].Coordinates.([]float64)[0] = 99
+	copied[1].Coordinates.([][]float64)[0][0] = 99
+	if fc.Features[0].Geometry.(*Point).Coordinates[0] != 5 || fc.Features[1].Geometry.(*MultiPoint).Coordinates[0][0] != 1 {
+		t.Error("Expected the topology not to share positions with the input")
+	}
+
+	if _, err = FromTopoJSON(topology, "missing"); err == nil {
+		t.Error("Expected an error for a missing object")
+	}
+	if _, err = TopologyFromBytes([]byte(`{"type":"FeatureCollection"}`)); err == nil {
+		t.Error("Expected an error for a non-topology")
+	}
+	topology.Arcs = topology.Arcs[:1]
+	if _, err = FromTopoJSON(topology, "all"); err == nil {
+		t.Error("Expected an error for a missing arc")
+	}
+}
+
+func TestTopoJSONQuantization(t *testing.T) {
+	fc := NewFeatureCollection([]*Feature{
+		squareFeature("a", 0, 0), squareFeature("b", 1, 0),
+		NewFeature(NewLineString([][]float64{{0, 0}, {0.0001, 0.0001}}), "tiny", nil),
+		NewFeature(NewPoint([]float64{0.5, 0.5, 7}), "point", nil)})
+	topology := ToTopoJSON(fc, "data", 101)
+	if topology.Transform == nil {
+		t.Fatal("Expected a transform")
+	}
+	if actual := fmt.Sprint(*topology.Transform); actual != "{[0.02 0.01] [0 0]}" {
+		t.Errorf("Expected the transform to cover the data, got %v", actual)
+	}
+	for _, arc := range topology.Arcs {
+		for _, position := range arc {
+			if position[0] != float64(int(position[0])) || position[1] != float64(int(position[1])) {
+				t.Errorf("Expected quantized positions, got %v", arc)
+			}
+		}
+	}
+	if actual := fmt.Sprint(topology.Arcs[0]); actual != "[[50 0] [0 100]]" {
+		t.Errorf("Expected the shared edge to be delta-encoded, got %v", actual)
+	}
+
+	result, err := FromTopoJSON(topology, "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for inx := 0; inx < 2; inx++ {
+		if !EqualsTopologically(result.Features[inx].Geometry, fc.Features[inx].Geometry, 1e-9) {
+			t.Errorf("Expected %v, got %v", fc.Features[inx].Geometry, result.Features[inx].Geometry)
+		}
+	}
+	if line := result.Features[2].Geometry.(*LineString); len(line.Coordinates) != 0 {
+		t.Errorf("Expected the collapsed line to be empty, got %v", line.Coordinates)
+	}
+	if point := result.Features[3].Geometry.(*Point); fmt.Sprint(point.Coordinates) != "[0.5 0.5 7]" {
+		t.Errorf("Expected the point with its elevation, got %v", point.Coordinates)
+	}
+	if fc.Features[0].Geometry.(*Polygon).Coordinates[0][1][0] != 1 {
+		t.Error("Expected the source geometries to be unchanged")
+	}
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// Triangulate breaks a Polygon or MultiPolygon into triangles by ear clipping,
+// in the form used by WebGL: a flat array of x, y vertex pairs taken from the
+// rings in order (without their closing positions) and a flat array of
+// vertex indexes, three per counterclockwise triangle.
+// Holes are joined to their exterior ring before clipping.
+// Other geometries produce empty arrays.
+func Triangulate(geometry interface{}) ([]float64, []int) {
+	var (
+		vertices []float64
+		indices  []int
+	)
+	var polygons [][][][]float64
+	switch typed := geometry.(type) {
+	case *Polygon:
+		polygons = [][][][]float64{typed.Coordinates}
+	case *MultiPolygon:
+		polygons = typed.Coordinates
+	}
+	for _, polygon := range polygons {
+		vertices, indices = triangulatePolygon(polygon, vertices, indices)
+	}
+	return vertices, indices
+}
+
+// TriangulateToFeatures returns the triangles of a Polygon or MultiPolygon
+// as a FeatureCollection of triangular Polygons
+func TriangulateToFeatures(geometry interface{}) *FeatureCollection {
+	vertices, indices := Triangulate(geometry)
+	features := make([]*Feature, 0, len(indices)/3)
+	vertex := func(index int) []float64 {
+		return []float64{vertices[2*index], vertices[2*index+1]}
+	}
+	for inx := 0; inx+2 < len(indices); inx += 3 {
+		ring := [][]float64{vertex(indices[inx]), vertex(indices[inx+1]), vertex(indices[inx+2]), vertex(indices[inx])}
+		features = append(features, NewFeature(NewPolygon([][][]float64{ring}), nil, nil))
+	}
+	return NewFeatureCollection(features)
+}
+
+// earNode is a vertex in the circular list of a ring being clipped
+type earNode struct {
+	index      int
+	x, y       float64
+	prev, next *earNode
+	// removed is set once the vertex is clipped, and listed once it is
+	// among the reflex vertices that may lie within an ear
+	removed, listed bool
+}
+
+func (node *earNode) position() []float64 {
+	return []float64{node.x, node.y}
+}
+
+func (node *earNode) remove() {
+	node.prev.next = node.next
+	node.next.prev = node.prev
+	node.removed = true
+}
+
+// triangulatePolygon appends the vertices and triangles of a polygon
+func triangulatePolygon(polygon [][][]float64, vertices []float64, indices []int) ([]float64, []int) {
+	if len(polygon) == 0 {
+		return vertices, indices
+	}
+	outer := earRing(polygon[0], true, &vertices)
+	if outer == nil {
+		return vertices, indices
+	}
+	var holes []*earNode
+	for _, ring := range polygon[1:] {
+		if hole := earRing(ring, false, &vertices); hole != nil {
+			holes = append(holes, hole)
+		}
+	}
+	// Join holes from the east so that each bridge sees the holes already joined
+	sort.Slice(holes, func(i, j int) bool {
+		return rightmost(holes[i]).x > rightmost(holes[j]).x
+	})
+	for inx, hole := range holes {
+		bridgeHole(outer, hole, holes[inx+1:])
+	}
+	return vertices, clipEars(outer, indices)
+}
+
+// earRing appends the positions of a ring to the vertices and returns it
+// as a circular list oriented counterclockwise for exterior rings
+// and clockwise for holes, or nil if it has fewer than three positions
+func earRing(ring [][]float64, exterior bool, vertices *[]float64) *earNode {
+	if len(ring) > 1 && samePosition(ring[0], ring[len(ring)-1]) {
+		ring = ring[:len(ring)-1]
+	}
+	if len(ring) < 3 {
+		return nil
+	}
+	first := len(*vertices) / 2
+	nodes := make([]*earNode, len(ring))
+	for inx, position := range ring {
+		*vertices = append(*vertices, position[0], position[1])
+		nodes[inx] = &earNode{index: first + inx, x: position[0], y: position[1]}
+	}
+	if area := ringArea(ring); (area < 0) == exterior {
+		reverseNodes(nodes)
+	}
+	for inx, node := range nodes {
+		node.next = nodes[(inx+1)%len(nodes)]
+		node.prev = nodes[(inx+len(nodes)-1)%len(nodes)]
+	}
+	return nodes[0]
+}
+
+func reverseNodes(nodes []*earNode) {
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+}
+
+func rightmost(start *earNode) *earNode {
+	result := start
+	for node := start.next; node != start; node = node.next {
+		if node.x > result.x {
+			result = node
+		}
+	}
+	return result
+}
+
+// bridgeHole joins a hole into the outer list with a pair of coincident edges
+// from its easternmost vertex to the nearest outer vertex that can see it
+func bridgeHole(outer, hole *earNode, remaining []*earNode) {
+	from := rightmost(hole)
+	var candidates []*earNode
+	node := outer
+	for {
+		candidates = append(candidates, node)
+		if node = node.next; node == outer {
+			break
+		}
+	}
+	distance := func(node *earNode) float64 {
+		return math.Hypot(node.x-from.x, node.y-from.y)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i]) < distance(candidates[j])
+	})
+	to := candidates[0]
+	for _, candidate := range candidates {
+		if bridgeVisible(from, candidate, append([]*earNode{outer, hole}, remaining...)) {
+			to = candidate
+			break
+		}
+	}
+
+	// Splice: to -> from -> ...hole... -> from' -> to' -> to.next
+	fromCopy := &earNode{index: from.index, x: from.x, y: from.y}
+	toCopy := &earNode{index: to.index, x: to.x, y: to.y}
+	toNext, fromPrev := to.next, from.prev
+	to.next, from.prev = from, to
+	fromPrev.next, fromCopy.prev = fromCopy, fromPrev
+	fromCopy.next, toCopy.prev = toCopy, fromCopy
+	toCopy.next, toNext.prev = toNext, toCopy
+}
+
+// bridgeVisible returns true if the segment between two vertices
+// crosses no edge of the lists provided and runs through the polygon interior
+func bridgeVisible(from, to *earNode, rings []*earNode) bool {
+	a, b := from.position(), to.position()
+	for _, start := range rings {
+		node := start
+		for {
+			c, d := node.position(), node.next.position()
+			if segmentsCross(a, b, c, d) || (onSegment(c, a, b) && !samePosition(c, a) && !samePosition(c, b)) {
+				return false
+			}
+			if node = node.next; node == start {
+				break
+			}
+		}
+	}
+	// The bridge must leave the outer vertex into the polygon, between its neighbours
+	return locallyInside(to, from)
+}
+
+// locallyInside returns true if the direction from a node towards a position
+// lies within the interior angle at the node
+func locallyInside(node, toward *earNode) bool {
+	p := toward.position()
+	prev, curr, next := node.prev.position(), node.position(), node.next.position()
+	if cross(prev, curr, next) >= 0 {
+		return cross(curr, next, p) >= 0 && cross(prev, curr, p) >= 0
+	}
+	return cross(curr, next, p) >= 0 || cross(prev, curr, p) >= 0
+}
+
+// clipEars appends the triangles of a ring, clipping one ear at a time.
+// Only reflex and collinear vertices can lie within an ear, so they are kept
+// in a list to test candidates against, and since clipping an ear only changes
+// its neighbours, they are the only vertices tested again afterwards.
+func clipEars(start *earNode, indices []int) []int {
+	var (
+		remaining  int
+		reflex     []*earNode
+		candidates []*earNode
+	)
+	track := func(node *earNode) {
+		if !node.listed && convexity(node) <= 0 {
+			node.listed = true
+			reflex = append(reflex, node)
+		}
+	}
+	node := start
+	for {
+		remaining++
+		track(node)
+		candidates = append(candidates, node)
+		if node = node.next; node == start {
+			break
+		}
+	}
+	// Candidates are taken from the end, so test them in ring order
+	reverseNodes(candidates)
+
+	clip := func(ear *earNode) {
+		ear.remove()
+		remaining--
+		track(ear.prev)
+		track(ear.next)
+		// The next vertex is tested first, continuing around the ring
+		candidates = append(candidates, ear.prev, ear.next)
+		node = ear.next
+	}
+	for remaining >= 3 {
+		var ear *earNode
+		for ear == nil && len(candidates) > 0 {
+			candidate := candidates[len(candidates)-1]
+			candidates = candidates[:len(candidates)-1]
+			if !candidate.removed && convexity(candidate) > 0 && isEar(candidate, reflex) {
+				ear = candidate
+			}
+		}
+		if ear == nil {
+			// Collinear vertices block ears but leave no triangle when removed
+			if flat := findNode(node, remaining, func(candidate *earNode) bool {
+				return convexity(candidate) == 0
+			}); flat != nil {
+				clip(flat)
+				continue
+			}
+			// Only invalid input, such as a self-intersecting ring, gets here
+			if ear = findNode(node, remaining, func(candidate *earNode) bool {
+				return convexity(candidate) > 0 && isEar(candidate, reflex)
+			}); ear == nil {
+				if ear = findNode(node, remaining, func(candidate *earNode) bool {
+					return convexity(candidate) > 0
+				}); ear == nil {
+					return indices
+				}
+			}
+		}
+		indices = append(indices, ear.prev.index, ear.index, ear.next.index)
+		clip(ear)
+	}
+	return indices
+}
+
+// findNode returns the first of the nodes from start that satisfies the test, or nil
+func findNode(start *earNode, count int, test func(*earNode) bool) *earNode {
+	node := start
+	for inx := 0; inx < count; inx++ {
+		if test(node) {
+			return node
+		}
+		node = node.next
+	}
+	return nil
+}
+
+func convexity(node *earNode) float64 {
+	return cross(node.prev.position(), node.position(), node.next.position())
+}
+
+// isEar returns true if none of the reflex vertices provided
+// lies within the triangle at the node
+func isEar(node *earNode, reflex []*earNode) bool {
+	a, b, c := node.prev.position(), node.position(), node.next.position()
+	for _, other := range reflex {
+		if other.removed || other == node || other == node.prev || other == node.next {
+			continue
+		}
+		p := other.position()
+		if samePosition(p, a) || samePosition(p, b) || samePosition(p, c) {
+			continue
+		}
+		if cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+// triangulatedArea returns the total area of the triangles,
+// failing the test if any is not counterclockwise
+func triangulatedArea(t *testing.T, vertices []float64, indices []int) float64 {
+	var result float64
+	for inx := 0; inx+2 < len(indices); inx += 3 {
+		var triangle [][]float64
+		for _, index := range indices[inx : inx+3] {
+			triangle = append(triangle, []float64{vertices[2*index], vertices[2*index+1]})
+		}
+		area := ringArea(triangle)
+		if area <= 0 {
+			t.Errorf("Expected a counterclockwise triangle, got %v", triangle)
+		}
+		result += area
+	}
+	return result
+}
+
+func TestTriangulate(t *testing.T) {
+	square := NewPolygon([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}})
+	vertices, indices := Triangulate(square)
+	if len(vertices) != 8 || len(indices) != 6 {
+		t.Errorf("Expected 4 vertices and 2 triangles, got %v %v", vertices, indices)
+	}
+	testClose(t, "square", 100, triangulatedArea(t, vertices, indices), 1e-9)
+
+	// A clockwise comb with deep notches
+	comb := NewPolygon([][][]float64{{{0, 0}, {0, 10}, {2, 10}, {2, 2}, {4, 2}, {4, 10}, {6, 10}, {6, 2}, {8, 2}, {8, 10}, {10, 10}, {10, 0}, {0, 0}}})
+	vertices, indices = Triangulate(comb)
+	if len(indices) != 3*10 {
+		t.Errorf("Expected 10 triangles, got %v", len(indices)/3)
+	}
+	testClose(t, "comb", 68, triangulatedArea(t, vertices, indices), 1e-9)
+
+	holes := NewPolygon([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+		{{6, 6}, {8, 6}, {8, 8}, {6, 8}, {6, 6}},
+		{{6, 2}, {8, 2}, {7, 4}, {6, 2}}})
+	vertices, indices = Triangulate(holes)
+	if len(vertices) != 2*15 {
+		t.Errorf("Expected 15 vertices, got %v", len(vertices)/2)
+	}
+	// A polygon with n vertices and h holes has n + 2h - 2 triangles
+	if len(indices) != 3*(15+2*3-2) {
+		t.Errorf("Expected 19 triangles, got %v", len(indices)/3)
+	}
+	testClose(t, "holes", 100-4-4-2, triangulatedArea(t, vertices, indices), 1e-9)
+
+	multi := NewMultiPolygon([][][][]float64{square.Coordinates, {{{20, 0}, {30, 0}, {25, 5}, {20, 0}}}})
+	vertices, indices = Triangulate(multi)
+	testClose(t, "multipolygon", 125, triangulatedArea(t, vertices, indices), 1e-9)
+	for _, index := range indices[6:] {
+		if index < 4 {
+			t.Errorf("Expected the second polygon to use its own vertices, got %v", indices)
+		}
+	}
+
+	fc := TriangulateToFeatures(holes)
+	if len(fc.Features) != 19 {
+		t.Errorf("Expected 19 triangles, got %v", len(fc.Features))
+	}
+	expected := `{"type":"Polygon","coordinates":[[[0,10],[0,0],[10,0],[0,10]]]}`
+	if result := TriangulateToFeatures(square).Features[0].Geometry.(*Polygon).String(); result != expected {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// A clockwise saw with many teeth has many reflex vertices
+	var saw [][]float64
+	const teeth = 2000
+	for inx := 0; inx < teeth; inx++ {
+		x := float64(inx)
+		saw = append(saw, []float64{x, 0}, []float64{x + 0.5, 10}, []float64{x + 1, 1})
+	}
+	saw = append(saw, []float64{teeth, -1}, []float64{0, -1}, []float64{0, 0})
+	vertices, indices = Triangulate(NewPolygon([][][]float64{saw}))
+	if len(indices) != 3*(len(saw)-1-2) {
+		t.Errorf("Expected %v triangles, got %v", len(saw)-1-2, len(indices)/3)
+	}
+	testClose(t, "saw", -ringArea(saw), triangulatedArea(t, vertices, indices), 1e-6)
+
+	if vertices, indices = Triangulate(NewLineString([][]float64{{0, 0}, {1, 1}})); len(vertices) != 0 || len(indices) != 0 {
+		t.Error("Expected no triangles for a LineString")
+	}
+}
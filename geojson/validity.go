@@ -0,0 +1,446 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Reasons a geometry may be invalid
+const (
+	InvalidCoordinate    = "Invalid coordinate"
+	TooFewPoints         = "Too few points"
+	RingNotClosed        = "Ring not closed"
+	Spike                = "Spike"
+	RingSelfIntersection = "Ring self-intersection"
+	RingsIntersect       = "Rings intersect"
+	HoleOutsideShell     = "Hole lies outside shell"
+	NestedHoles          = "Nested holes"
+	NestedShells         = "Nested shells"
+	UnsupportedGeometry  = "Unsupported geometry"
+)
+
+// InvalidGeometryError describes the first OGC Simple Features
+// violation found in a geometry
+type InvalidGeometryError struct {
+	Reason   string
+	Location []float64
+}
+
+func (err *InvalidGeometryError) Error() string {
+	if err.Location == nil {
+		return err.Reason
+	}
+	return fmt.Sprintf("%v at %v", err.Reason, err.Location)
+}
+
+func invalid(reason string, location []float64) *InvalidGeometryError {
+	return &InvalidGeometryError{Reason: reason, Location: clone1(location)}
+}
+
+// IsValid returns true if the geometry is valid according to the
+// OGC Simple Features specification. If it is not, the error returned
+// is an *InvalidGeometryError giving the reason and location of the first violation.
+// Only the first two ordinates of each position are considered.
+func IsValid(geometry interface{}) (bool, error) {
+	if err := validate(geometry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func validate(geometry interface{}) *InvalidGeometryError {
+	switch typed := geometry.(type) {
+	case *Point:
+		if typed == nil {
+			return invalid(TooFewPoints, nil)
+		}
+		return validatePosition(typed.Coordinates)
+	case *MultiPoint:
+		if typed == nil {
+			return invalid(TooFewPoints, nil)
+		}
+		for _, position := range typed.Coordinates {
+			if err := validatePosition(position); err != nil {
+				return err
+			}
+		}
+	case *LineString:
+		if typed == nil {
+			return invalid(TooFewPoints, nil)
+		}
+		return validateLine(typed.Coordinates)
+	case *MultiLineString:
+		if typed == nil {
+			return invalid(TooFewPoints, nil)
+		}
+		for _, line := range typed.Coordinates {
+			if err := validateLine(line); err != nil {
+				return err
+			}
+		}
+	case *Polygon:
+		if typed == nil {
+			return invalid(TooFewPoints, nil)
+		}
+		return validatePolygon(typed.Coordinates)
+	case *MultiPolygon:
+		if typed == nil {
+			return invalid(TooFewPoints, nil)
+		}
+		for _, polygon := range typed.Coordinates {
+			if err := validatePolygon(polygon); err != nil {
+				return err
+			}
+		}
+		return validatePolygonsDisjoint(typed.Coordinates)
+	case *GeometryCollection:
+		if typed == nil {
+			return invalid(TooFewPoints, nil)
+		}
+		for _, curr := range typed.Geometries {
+			if err := validate(curr); err != nil {
+				return err
+			}
+		}
+	default:
+		return invalid(UnsupportedGeometry, nil)
+	}
+	return nil
+}
+
+func validatePosition(position []float64) *InvalidGeometryError {
+	if len(position) < 2 {
+		return invalid(TooFewPoints, position)
+	}
+	for _, value := range position {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return invalid(InvalidCoordinate, position)
+		}
+	}
+	return nil
+}
+
+func validateLine(line [][]float64) *InvalidGeometryError {
+	for _, position := range line {
+		if err := validatePosition(position); err != nil {
+			return err
+		}
+	}
+	if len(removeDuplicatePositions(line)) < 2 {
+		var location []float64
+		if len(line) > 0 {
+			location = line[0]
+		}
+		return invalid(TooFewPoints, location)
+	}
+	return nil
+}
+
+func validateRing(ring [][]float64) *InvalidGeometryError {
+	for _, position := range ring {
+		if err := validatePosition(position); err != nil {
+			return err
+		}
+	}
+	if len(ring) == 0 {
+		return invalid(TooFewPoints, nil)
+	}
+	if !samePosition(ring[0], ring[len(ring)-1]) {
+		return invalid(RingNotClosed, ring[0])
+	}
+	open := removeDuplicatePositions(ring)
+	open = open[:len(open)-1]
+	if len(open) < 3 {
+		return invalid(TooFewPoints, ring[0])
+	}
+	count := len(open)
+	for inx := range open {
+		prev := open[(inx+count-1)%count]
+		curr := open[inx]
+		next := open[(inx+1)%count]
+		if samePosition(prev, next) || segmentsOverlap(prev, curr, curr, next) {
+			return invalid(Spike, curr)
+		}
+	}
+	for i := 0; i < count; i++ {
+		for j := i + 2; j < count; j++ {
+			if i == 0 && j == count-1 {
+				continue
+			}
+			if p := segmentIntersection(open[i], open[i+1], open[j], open[(j+1)%count]); p != nil {
+				return invalid(RingSelfIntersection, p)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePolygon(polygon [][][]float64) *InvalidGeometryError {
+	if len(polygon) == 0 {
+		return nil
+	}
+	for _, ring := range polygon {
+		if err := validateRing(ring); err != nil {
+			return err
+		}
+	}
+	for inx, hole := range polygon[1:] {
+		if p := ringsCross(polygon[0], hole); p != nil {
+			return invalid(RingsIntersect, p)
+		}
+		if !ringInsideRing(hole, polygon[0]) {
+			return invalid(HoleOutsideShell, hole[0])
+		}
+		for _, other := range polygon[inx+2:] {
+			if p := ringsCross(hole, other); p != nil {
+				return invalid(RingsIntersect, p)
+			}
+			if ringInsideRing(other, hole) {
+				return invalid(NestedHoles, other[0])
+			}
+			if ringInsideRing(hole, other) {
+				return invalid(NestedHoles, hole[0])
+			}
+		}
+	}
+	return nil
+}
+
+// ringsCross returns a position where the two rings cross or overlap,
+// or nil if they at most touch at single points
+func ringsCross(first, second [][]float64) []float64 {
+	for i := 1; i < len(first); i++ {
+		for j := 1; j < len(second); j++ {
+			a, b := first[i-1], first[i]
+			c, d := second[j-1], second[j]
+			if segmentsCross(a, b, c, d) || segmentsOverlap(a, b, c, d) {
+				return segmentIntersection(a, b, c, d)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePolygonsDisjoint(polygons [][][][]float64) *InvalidGeometryError {
+	for i, first := range polygons {
+		if len(first) == 0 {
+			continue
+		}
+		for _, second := range polygons[i+1:] {
+			if len(second) == 0 {
+				continue
+			}
+			for _, ring1 := range first {
+				for _, ring2 := range second {
+					if p := ringsCross(ring1, ring2); p != nil {
+						return invalid(RingsIntersect, p)
+					}
+				}
+			}
+			for _, position := range second[0] {
+				if pointInPolygon(position, first) == 1 {
+					return invalid(NestedShells, position)
+				}
+			}
+			for _, position := range first[0] {
+				if pointInPolygon(position, second) == 1 {
+					return invalid(NestedShells, position)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// MakeValid returns a valid version of a Polygon or MultiPolygon.
+// Rings are closed, invalid and duplicate positions and spikes are removed,
+// self-intersecting rings such as bow-ties are split into separate rings,
+// and holes outside every shell are dropped. The result is a Polygon,
+// or a MultiPolygon if repair produced more than one shell.
+// Overlapping parts of a MultiPolygon are not merged.
+// Other geometries are returned unchanged.
+func MakeValid(geometry interface{}) interface{} {
+	var polygons [][][][]float64
+	switch typed := geometry.(type) {
+	case *Polygon:
+		polygons = [][][][]float64{typed.Coordinates}
+	case *MultiPolygon:
+		polygons = typed.Coordinates
+	default:
+		return geometry
+	}
+
+	var result [][][][]float64
+	for _, polygon := range polygons {
+		result = append(result, makePolygonValid(polygon)...)
+	}
+	switch len(result) {
+	case 0:
+		return NewPolygon([][][]float64{})
+	case 1:
+		return NewPolygon(result[0])
+	}
+	return NewMultiPolygon(result)
+}
+
+func makePolygonValid(polygon [][][]float64) [][][][]float64 {
+	if len(polygon) == 0 {
+		return nil
+	}
+	type shell struct {
+		ring  [][]float64
+		area  float64
+		holes [][][]float64
+	}
+	var (
+		shells []*shell
+		holes  [][][]float64
+	)
+	for _, piece := range repairRing(polygon[0]) {
+		shells = append(shells, &shell{ring: piece, area: math.Abs(ringArea(piece))})
+	}
+	for _, ring := range polygon[1:] {
+		holes = append(holes, repairRing(ring)...)
+	}
+
+	// A piece of the shell lying inside a larger piece is really a hole
+	sort.SliceStable(shells, func(i, j int) bool { return shells[i].area > shells[j].area })
+	var accepted []*shell
+	for _, candidate := range shells {
+		isHole := false
+		for _, outer := range accepted {
+			if ringInsideRing(candidate.ring, outer.ring) {
+				outer.holes = append(outer.holes, candidate.ring)
+				isHole = true
+				break
+			}
+		}
+		if !isHole {
+			accepted = append(accepted, candidate)
+		}
+	}
+
+	// Each hole goes to the smallest shell containing it
+	for _, hole := range holes {
+		var best *shell
+		for _, outer := range accepted {
+			if ringInsideRing(hole, outer.ring) && (best == nil || outer.area < best.area) {
+				best = outer
+			}
+		}
+		if best != nil {
+			best.holes = append(best.holes, hole)
+		}
+	}
+
+	var result [][][][]float64
+	for _, outer := range accepted {
+		polygon := [][][]float64{orientRing(outer.ring, true)}
+		for _, hole := range outer.holes {
+			polygon = append(polygon, orientRing(hole, false))
+		}
+		result = append(result, polygon)
+	}
+	return result
+}
+
+// repairRing returns the simple, closed rings with area that make up the ring provided
+func repairRing(ring [][]float64) [][][]float64 {
+	var cleaned [][]float64
+	for _, position := range ring {
+		if validatePosition(position) == nil {
+			cleaned = append(cleaned, clone1(position))
+		}
+	}
+	cleaned = removeDuplicatePositions(cleaned)
+	if len(cleaned) > 1 && samePosition(cleaned[0], cleaned[len(cleaned)-1]) {
+		cleaned = cleaned[:len(cleaned)-1]
+	}
+	return splitRing(removeSpikes(cleaned))
+}
+
+// removeSpikes removes positions from an open ring where the boundary
+// doubles back on itself
+func removeSpikes(open [][]float64) [][]float64 {
+	for changed := true; changed && len(open) >= 3; {
+		changed = false
+		count := len(open)
+		for inx := 0; inx < count; inx++ {
+			prev := open[(inx+count-1)%count]
+			curr := open[inx]
+			next := open[(inx+1)%count]
+			if samePosition(prev, next) || segmentsOverlap(prev, curr, curr, next) {
+				open = append(open[:inx:inx], open[inx+1:]...)
+				open = removeDuplicatePositions(open)
+				if len(open) > 1 && samePosition(open[0], open[len(open)-1]) {
+					open = open[:len(open)-1]
+				}
+				changed = true
+				break
+			}
+		}
+	}
+	return open
+}
+
+// splitRing splits an open ring at its first self-intersection and recurses,
+// returning closed rings that have area
+func splitRing(open [][]float64) [][][]float64 {
+	count := len(open)
+	if count < 3 {
+		return nil
+	}
+	for i := 0; i < count; i++ {
+		for j := i + 2; j < count; j++ {
+			if i == 0 && j == count-1 {
+				continue
+			}
+			p := segmentIntersection(open[i], open[i+1], open[j], open[(j+1)%count])
+			if p == nil {
+				continue
+			}
+			first := append([][]float64{p}, open[i+1:j+1]...)
+			second := append(append(append([][]float64{}, open[:i+1]...), p), open[j+1:]...)
+			return append(splitRing(tidyRing(first)), splitRing(tidyRing(second))...)
+		}
+	}
+	if ringArea(open) == 0 {
+		return nil
+	}
+	return [][][]float64{append(append([][]float64{}, open...), clone1(open[0]))}
+}
+
+// tidyRing removes duplicates and spikes from an open ring
+func tidyRing(open [][]float64) [][]float64 {
+	open = removeDuplicatePositions(open)
+	if len(open) > 1 && samePosition(open[0], open[len(open)-1]) {
+		open = open[:len(open)-1]
+	}
+	return removeSpikes(open)
+}
+
+// orientRing returns the closed ring oriented counterclockwise if it is
+// an exterior ring and clockwise otherwise, per RFC 7946
+func orientRing(ring [][]float64, exterior bool) [][]float64 {
+	if area := ringArea(ring); (exterior && area < 0) || (!exterior && area > 0) {
+		return reverseSequence(clone2(ring))
+	}
+	return ring
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIsValid(t *testing.T) {
+	for _, fileName := range inputFilesForWKT {
+		gj, err := ParseFile(fileName)
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+		if ok, err := IsValid(gj); !ok {
+			t.Errorf("Expected %v to be valid: %v", fileName, err)
+		}
+	}
+
+	tests := []struct {
+		geometry interface{}
+		reason   string
+		location []float64
+	}{
+		{NewPoint([]float64{math.NaN(), 1}), InvalidCoordinate, nil},
+		{NewLineString([][]float64{{1, 1}, {1, 1}}), TooFewPoints, []float64{1, 1}},
+		{NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}), RingNotClosed, []float64{0, 0}},
+		{NewPolygon([][][]float64{{{0, 0}, {1, 0}, {0, 0}}}), TooFewPoints, []float64{0, 0}},
+		{NewPolygon([][][]float64{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}, {-1, 0}, {0, 0}}}), Spike, []float64{-1, 0}},
+		{NewPolygon([][][]float64{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}}), RingSelfIntersection, []float64{1, 1}},
+		{NewPolygon([][][]float64{
+			{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}},
+			{{3, 3}, {4, 3}, {4, 4}, {3, 3}}}), HoleOutsideShell, []float64{3, 3}},
+		{NewPolygon([][][]float64{
+			{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}},
+			{{1, 1}, {3, 1}, {3, 1.5}, {1, 1}}}), RingsIntersect, []float64{2, 1}},
+		{NewPolygon([][][]float64{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			{{1, 1}, {9, 1}, {9, 9}, {1, 9}, {1, 1}},
+			{{2, 2}, {3, 2}, {3, 3}, {2, 2}}}), NestedHoles, []float64{2, 2}},
+		{NewMultiPolygon([][][][]float64{
+			{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+			{{{2, 2}, {3, 2}, {3, 3}, {2, 2}}}}), NestedShells, []float64{2, 2}},
+		{"foo", UnsupportedGeometry, nil},
+		{(*Point)(nil), TooFewPoints, nil},
+		{(*MultiPoint)(nil), TooFewPoints, nil},
+		{(*LineString)(nil), TooFewPoints, nil},
+		{(*MultiLineString)(nil), TooFewPoints, nil},
+		{(*Polygon)(nil), TooFewPoints, nil},
+		{(*MultiPolygon)(nil), TooFewPoints, nil},
+		{(*GeometryCollection)(nil), TooFewPoints, nil},
+	}
+	for _, test := range tests {
+		ok, err := IsValid(test.geometry)
+		if ok {
+			t.Errorf("Expected %v to be invalid", test.geometry)
+			continue
+		}
+		invalidErr := err.(*InvalidGeometryError)
+		if invalidErr.Reason != test.reason || (test.location != nil && !samePosition(invalidErr.Location, test.location)) {
+			t.Errorf("Expected %v at %v for %v, got %v", test.reason, test.location, test.geometry, err)
+		}
+	}
+}
+
+func TestMakeValid(t *testing.T) {
+	bowtie := NewPolygon([][][]float64{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}})
+	result, ok := MakeValid(bowtie).(*MultiPolygon)
+	if !ok {
+		t.Fatalf("Expected a MultiPolygon, got %v", MakeValid(bowtie))
+	}
+	expected := NewMultiPolygon([][][][]float64{
+		{{{0, 0}, {1, 1}, {0, 2}, {0, 0}}},
+		{{{1, 1}, {2, 0}, {2, 2}, {1, 1}}}})
+	if !EqualsTopologically(result, expected, 0) {
+		t.Errorf("Unexpected bow-tie repair: %v", result)
+	}
+	if ok, err := IsValid(result); !ok {
+		t.Errorf("Expected a valid result: %v", err)
+	}
+
+	messy := NewPolygon([][][]float64{
+		{{0, 0}, {0, 10}, {10, 10}, {10, 10}, {10, 0}, {12, 0}, {10, 0}},
+		{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}},
+		{{20, 20}, {21, 20}, {21, 21}, {20, 20}}})
+	polygon, ok := MakeValid(messy).(*Polygon)
+	if !ok {
+		t.Fatalf("Expected a Polygon, got %v", MakeValid(messy))
+	}
+	expectedPolygon := `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,4],[4,4],[4,2],[2,2]]]}`
+	if normalized := Normalize(polygon).(*Polygon); normalized.String() != expectedPolygon {
+		t.Errorf("Expected %v, got %v", expectedPolygon, normalized.String())
+	}
+	if ok, err := IsValid(polygon); !ok {
+		t.Errorf("Expected a valid result: %v", err)
+	}
+
+	// A ring touching itself encloses a hole
+	inverted := NewPolygon([][][]float64{{{0, 0}, {10, 0}, {10, 10}, {5, 10}, {4, 6}, {6, 6}, {5, 10}, {0, 10}, {0, 0}}})
+	if polygon, ok = MakeValid(inverted).(*Polygon); !ok || len(polygon.Coordinates) != 2 {
+		t.Errorf("Expected a Polygon with a hole, got %v", MakeValid(inverted))
+	} else if ok, err := IsValid(polygon); !ok {
+		t.Errorf("Expected a valid result: %v", err)
+	}
+
+	point := NewPoint([]float64{1, 2})
+	if MakeValid(point) != point {
+		t.Error("Expected a Point to be returned unchanged")
+	}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// Delaunay returns the Delaunay triangulation of the Point features in the
+// collection as a FeatureCollection of triangular Polygons.
+// Features that are not Points and repeated positions are ignored,
+// and points that are all collinear produce no triangles.
+func Delaunay(fc *FeatureCollection) *FeatureCollection {
+	_, sites := delaunaySites(fc)
+	triangles := bowyerWatson(sites)
+	features := make([]*Feature, 0, len(triangles))
+	for _, triangle := range triangles {
+		ring := [][]float64{
+			clone1(sites[triangle[0]]),
+			clone1(sites[triangle[1]]),
+			clone1(sites[triangle[2]]),
+			clone1(sites[triangle[0]])}
+		features = append(features, NewFeature(NewPolygon([][][]float64{ring}), nil, nil))
+	}
+	return NewFeatureCollection(features)
+}
+
+// Voronoi returns the Voronoi diagram of the Point features in the collection,
+// clipped to the bounding box, as a FeatureCollection of Polygon cells.
+// Each cell has a copy of the ID and properties of its source feature.
+// If the bounding box is empty, the extent of the points is used with a margin.
+// Features that are not Points, repeated positions, and points outside
+// the bounding box produce no cells.
+func Voronoi(fc *FeatureCollection, clipBbox BoundingBox) *FeatureCollection {
+	sources, sites := delaunaySites(fc)
+	if len(sites) == 0 {
+		return NewFeatureCollection([]*Feature{})
+	}
+	west, south, east, north := voronoiExtent(sites, clipBbox)
+
+	// Each cell is bounded by its Delaunay neighbours; without a triangulation
+	// every other site is a potential neighbour
+	neighbours := make([]map[int]bool, len(sites))
+	for inx := range neighbours {
+		neighbours[inx] = make(map[int]bool)
+	}
+	triangles := bowyerWatson(sites)
+	for _, triangle := range triangles {
+		for i := 0; i < 3; i++ {
+			neighbours[triangle[i]][triangle[(i+1)%3]] = true
+			neighbours[triangle[(i+1)%3]][triangle[i]] = true
+		}
+	}
+
+	var features []*Feature
+	for inx, site := range sites {
+		cell := [][]float64{{west, south}, {east, south}, {east, north}, {west, north}}
+		for other := range sites {
+			if other == inx || (len(triangles) > 0 && !neighbours[inx][other]) {
+				continue
+			}
+			cell = clipHalfPlane(cell, site, sites[other])
+		}
+		if len(cell) < 3 || pointInRing(site, cell) < 0 {
+			continue
+		}
+		cell = append(cell, clone1(cell[0]))
+		source := sources[inx]
+		features = append(features, NewFeature(NewPolygon([][][]float64{cell}),
+			cloneValue(source.ID), cloneProperties(source.Properties)))
+	}
+	return NewFeatureCollection(features)
+}
+
+// delaunaySites returns the Point features of the collection
+// with distinct positions, and those positions
+func delaunaySites(fc *FeatureCollection) ([]*Feature, [][]float64) {
+	var (
+		sources []*Feature
+		sites   [][]float64
+		seen    = make(map[[2]float64]bool)
+	)
+	if fc == nil {
+		return sources, sites
+	}
+	for _, feature := range fc.Features {
+		if feature == nil {
+			continue
+		}
+		point, ok := feature.Geometry.(*Point)
+		if !ok || point == nil || len(point.Coordinates) < 2 {
+			continue
+		}
+		key := [2]float64{point.Coordinates[0], point.Coordinates[1]}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		sources = append(sources, feature)
+		sites = append(sites, point.Coordinates)
+	}
+	return sources, sites
+}
+
+// delaunayGhost is the vertex at infinity that ghost triangles share.
+// A ghost triangle joins an edge of the convex hull to it, so that sites
+// outside the hull can be inserted without an enclosing super triangle.
+const delaunayGhost = -1
+
+// delaunayTriangle holds the indexes of the vertices of a triangle in
+// counterclockwise order; in a ghost triangle the vertex at infinity lies
+// to the left of the edge from the vertex after it to the one before it
+type delaunayTriangle [3]int
+
+// circumcircleContains returns true if the position lies strictly inside the
+// triangle's circumcircle. The circumcircle of a ghost triangle is the open
+// half-plane beyond its hull edge, together with the open edge itself.
+func (triangle delaunayTriangle) circumcircleContains(p []float64, positions [][]float64) bool {
+	for inx, vertex := range triangle {
+		if vertex != delaunayGhost {
+			continue
+		}
+		u, v := positions[triangle[(inx+1)%3]], positions[triangle[(inx+2)%3]]
+		if side := cross(u, v, p); side != 0 {
+			return side > 0
+		}
+		return (p[0]-u[0])*(v[0]-u[0])+(p[1]-u[1])*(v[1]-u[1]) > 0 &&
+			(p[0]-v[0])*(u[0]-v[0])+(p[1]-v[1])*(u[1]-v[1]) > 0
+	}
+	return inCircle(positions[triangle[0]], positions[triangle[1]], positions[triangle[2]], p) > 0
+}
+
+// inCircle is positive if d lies inside the circle through the
+// counterclockwise triangle a, b, c, negative if outside, and zero if on it
+func inCircle(a, b, c, d []float64) float64 {
+	adx, ady := a[0]-d[0], a[1]-d[1]
+	bdx, bdy := b[0]-d[0], b[1]-d[1]
+	cdx, cdy := c[0]-d[0], c[1]-d[1]
+	return (adx*adx+ady*ady)*(bdx*cdy-cdx*bdy) +
+		(bdx*bdx+bdy*bdy)*(cdx*ady-adx*cdy) +
+		(cdx*cdx+cdy*cdy)*(adx*bdy-bdx*ady)
+}
+
+// bowyerWatson returns the Delaunay triangles of the sites as counterclockwise
+// triples of indexes, using the Bowyer-Watson algorithm.
+// The hull is bounded by ghost triangles rather than a super triangle,
+// whose finite vertices could otherwise displace triangles along the hull.
+func bowyerWatson(sites [][]float64) [][3]int {
+	// Start from the first three sites that are not collinear
+	third := -1
+	for inx := 2; inx < len(sites); inx++ {
+		if cross(sites[0], sites[1], sites[inx]) != 0 {
+			third = inx
+			break
+		}
+	}
+	if third < 0 {
+		return nil
+	}
+	a, b := 0, 1
+	if cross(sites[a], sites[b], sites[third]) < 0 {
+		a, b = b, a
+	}
+	triangles := []delaunayTriangle{
+		{a, b, third},
+		{b, a, delaunayGhost}, {third, b, delaunayGhost}, {a, third, delaunayGhost}}
+
+	for inx, site := range sites {
+		if inx < 2 || inx == third {
+			continue
+		}
+		var (
+			kept  []delaunayTriangle
+			edges = make(map[[2]int]bool)
+		)
+		for _, triangle := range triangles {
+			if !triangle.circumcircleContains(site, sites) {
+				kept = append(kept, triangle)
+				continue
+			}
+			for i := 0; i < 3; i++ {
+				edges[[2]int{triangle[i], triangle[(i+1)%3]}] = true
+			}
+		}
+		// Edges of removed triangles that are not shared with another bound the cavity
+		for edge := range edges {
+			if !edges[[2]int{edge[1], edge[0]}] {
+				kept = append(kept, delaunayTriangle{edge[0], edge[1], inx})
+			}
+		}
+		triangles = kept
+	}
+
+	var result [][3]int
+	for _, triangle := range triangles {
+		if triangle[0] != delaunayGhost && triangle[1] != delaunayGhost && triangle[2] != delaunayGhost {
+			result = append(result, triangle)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		for k := 0; k < 3; k++ {
+			if result[i][k] != result[j][k] {
+				return result[i][k] < result[j][k]
+			}
+		}
+		return false
+	})
+	return result
+}
+
+// voronoiExtent returns the west, south, east and north limits of a Voronoi diagram
+func voronoiExtent(sites [][]float64, clipBbox BoundingBox) (float64, float64, float64, float64) {
+	if len(clipBbox) >= 4 {
+		half := len(clipBbox) / 2
+		return clipBbox[0], clipBbox[1], clipBbox[half], clipBbox[half+1]
+	}
+	west, south := math.Inf(1), math.Inf(1)
+	east, north := math.Inf(-1), math.Inf(-1)
+	for _, site := range sites {
+		west, east = math.Min(west, site[0]), math.Max(east, site[0])
+		south, north = math.Min(south, site[1]), math.Max(north, site[1])
+	}
+	margin := math.Max(math.Max(east-west, north-south)*0.1, 1)
+	return west - margin, south - margin, east + margin, north + margin
+}
+
+// clipHalfPlane clips a convex polygon to the positions
+// at least as close to the site as to the other site
+func clipHalfPlane(polygon [][]float64, site, other []float64) [][]float64 {
+	nx, ny := other[0]-site[0], other[1]-site[1]
+	mx, my := (site[0]+other[0])/2, (site[1]+other[1])/2
+	side := func(p []float64) float64 {
+		return (p[0]-mx)*nx + (p[1]-my)*ny
+	}
+	var result [][]float64
+	for inx, curr := range polygon {
+		prev := polygon[(inx+len(polygon)-1)%len(polygon)]
+		currSide, prevSide := side(curr), side(prev)
+		if (currSide <= 0) != (prevSide <= 0) {
+			result = append(result, interpolatePosition(prev, curr, prevSide/(prevSide-currSide)))
+		}
+		if currSide <= 0 {
+			result = append(result, curr)
+		}
+	}
+	return result
+}
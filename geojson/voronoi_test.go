@@ -0,0 +1,127 @@
+/*
+Copyright 2016, RadiantBlue Technologies, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import "testing"
+
+func pointCollection(positions ...[]float64) *FeatureCollection {
+	var features []*Feature
+	for inx, position := range positions {
+		features = append(features, NewFeature(NewPoint(position), inx, map[string]interface{}{"index": inx}))
+	}
+	return NewFeatureCollection(features)
+}
+
+func TestDelaunay(t *testing.T) {
+	fc := pointCollection([]float64{0, 0}, []float64{10, 0}, []float64{10, 10}, []float64{0, 10}, []float64{5, 5}, []float64{5, 5})
+	fc.Features = append(fc.Features, NewFeature(NewLineString([][]float64{{0, 0}, {1, 1}}), nil, nil))
+	result := Delaunay(fc)
+	if len(result.Features) != 4 {
+		t.Fatalf("Expected 4 triangles, got %v", result.String())
+	}
+	var area float64
+	for _, feature := range result.Features {
+		ring := feature.Geometry.(*Polygon).Coordinates[0]
+		if len(ring) != 4 || !samePosition(ring[0], ring[3]) {
+			t.Errorf("Expected a closed triangle, got %v", ring)
+		}
+		triangleArea := ringArea(ring[:3])
+		if triangleArea <= 0 {
+			t.Errorf("Expected a counterclockwise triangle, got %v", ring)
+		}
+		area += triangleArea
+	}
+	testClose(t, "triangulated area", 100, area, 1e-9)
+
+	// No point lies inside the circumcircle of any triangle
+	random := pointCollection([]float64{3, 1}, []float64{7, 2}, []float64{1, 6}, []float64{8, 8}, []float64{4, 4}, []float64{6, 5}, []float64{2, 9})
+	for _, feature := range Delaunay(random).Features {
+		ring := feature.Geometry.(*Polygon).Coordinates[0]
+		for _, other := range random.Features {
+			position := other.Geometry.(*Point).Coordinates
+			if inCircle(ring[0], ring[1], ring[2], position) > 1e-9 {
+				t.Errorf("Position %v is inside the circumcircle of %v", position, ring)
+			}
+		}
+	}
+
+	// Widely spread, nearly collinear sites keep every triangle along the hull:
+	// all the sites on the shallow curve are on the hull, so there are
+	// 2n - h - 2 triangles, whose circumcircles are far larger than the sites' extent
+	var curve [][]float64
+	for x := 0.0; x <= 1000; x += 100 {
+		curve = append(curve, []float64{x, x * x * 1e-9})
+	}
+	result = Delaunay(pointCollection(curve...))
+	if expected := 2*len(curve) - len(curve) - 2; len(result.Features) != expected {
+		t.Errorf("Expected %v triangles, got %v", expected, len(result.Features))
+	}
+	area = 0
+	for _, feature := range result.Features {
+		area += ringArea(feature.Geometry.(*Polygon).Coordinates[0])
+	}
+	hull := append(append([][]float64{}, curve...), curve[0])
+	testClose(t, "curve hull area", ringArea(hull), area, 1e-9)
+
+	// Interior sites add two triangles each
+	hullSites := append(append([][]float64{}, curve...), []float64{500, 1000})
+	sites := append(append([][]float64{}, hullSites...), []float64{500, 500}, []float64{300, 300})
+	if expected := 2*len(sites) - len(hullSites) - 2; len(Delaunay(pointCollection(sites...)).Features) != expected {
+		t.Errorf("Expected %v triangles, got %v", expected, len(Delaunay(pointCollection(sites...)).Features))
+	}
+
+	if len(Delaunay(pointCollection([]float64{0, 0}, []float64{1, 1}, []float64{2, 2})).Features) != 0 {
+		t.Error("Expected no triangles for collinear points")
+	}
+}
+
+func TestVoronoi(t *testing.T) {
+	fc := pointCollection([]float64{2, 5}, []float64{8, 5})
+	result := Voronoi(fc, BoundingBox{0, 0, 10, 10})
+	if len(result.Features) != 2 {
+		t.Fatalf("Expected 2 cells, got %v", result.String())
+	}
+	expected := `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[5,0],[5,10],[0,10],[0,0]]]},"properties":{"index":0},"id":0}`
+	if result.Features[0].String() != expected {
+		t.Errorf("Expected %v, got %v", expected, result.Features[0].String())
+	}
+	fc.Features[0].Properties["index"] = 99
+	if result.Features[0].PropertyInt("index") != 0 {
+		t.Error("Expected the cell properties to be copied")
+	}
+
+	// The cells tile the bounding box
+	grid := pointCollection([]float64{1, 1}, []float64{4, 2}, []float64{8, 1}, []float64{2, 6}, []float64{5, 5}, []float64{9, 7}, []float64{6, 9}, []float64{20, 20})
+	cells := Voronoi(grid, BoundingBox{0, 0, 10, 10})
+	if len(cells.Features) != 7 {
+		t.Fatalf("Expected 7 cells, got %v", len(cells.Features))
+	}
+	var area float64
+	for _, cell := range cells.Features {
+		ring := cell.Geometry.(*Polygon).Coordinates[0]
+		site := grid.Features[cell.PropertyInt("index")].Geometry.(*Point).Coordinates
+		if pointInRing(site, ring) != 1 {
+			t.Errorf("Expected site %v inside its cell %v", site, ring)
+		}
+		area += ringArea(ring)
+	}
+	testClose(t, "cell area", 100, area, 1e-9)
+
+	if single := Voronoi(pointCollection([]float64{0, 0}), nil); len(single.Features) != 1 {
+		t.Errorf("Expected a single cell, got %v", single.String())
+	}
+}